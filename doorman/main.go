@@ -3,7 +3,9 @@ package doorman
 import (
 	"bytes"
 	"encoding/json"
+	"faucet-svc/internal/auth"
 	"faucet-svc/resources"
+	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
 	"io"
 	"net/http"
@@ -36,26 +38,32 @@ func (c *Connector) Authenticate(r *http.Request) (*resources.User, error) {
 		return nil, errors.Wrap(err, "failed to create authentication request out of incoming request")
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.Client.Do(req)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to do request")
 	}
+	defer resp.Body.Close()
 
 	switch resp.StatusCode {
+	case http.StatusOK:
 	case http.StatusBadRequest:
-		return nil, errors.New("failed to authenticate request due to invalid authentication info")
-	case http.StatusUnauthorized:
-		return nil, errors.New("requester is unauthorized")
-	case http.StatusInternalServerError:
-		return nil, errors.New("internal server error occurred while authentication")
+		return nil, errors.Wrap(auth.ErrUnauthorized, "failed to authenticate request due to invalid authentication info")
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, errors.Wrap(auth.ErrUnauthorized, "requester is unauthorized")
+	default:
+		return nil, errors.From(errors.New("unexpected authentication response status"), logan.F{
+			"status": resp.StatusCode,
+		})
 	}
 
 	var user resources.User
 	if err := json.NewDecoder(resp.Body).Decode(&user); err != nil {
 		return nil, errors.Wrap(err, "failed to decode user response")
 	}
+	if user.Id == "" {
+		return nil, errors.Wrap(auth.ErrUnauthorized, "authenticated user has no id")
+	}
 
-	r.Header.Set("User-Id", user.Id)
 	return &user, nil
 }
 
@@ -71,7 +79,7 @@ func createAuthenticationRequest(r *http.Request, endpoint *url.URL) (*http.Requ
 	}
 
 	r.Body = io.NopCloser(bytes.NewReader(rBody))
-	req, err := http.NewRequest("GET", drmUrl.String(), bytes.NewReader(rBody))
+	req, err := http.NewRequestWithContext(r.Context(), "GET", drmUrl.String(), bytes.NewReader(rBody))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create authentication request")
 	}
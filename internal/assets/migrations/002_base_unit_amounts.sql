@@ -0,0 +1,73 @@
+-- +migrate Up
+-- amounts used to be stored as human readable floats, converting them to base units with decimals of each chain.
+-- Decimals are known only from config, so they have to be listed in balance_decimals table by operator
+-- and token balances have to be moved aside and converted by hand, see README
+ALTER TABLE balances ADD COLUMN base_amount numeric(78, 0);
+
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    missing text;
+BEGIN
+    IF EXISTS (SELECT 1 FROM balances WHERE nullif(trim(token_address), '') IS NOT NULL) THEN
+        RAISE EXCEPTION 'token balances can''t be converted without decimals of the tokens, move them aside as described in README';
+    END IF;
+    IF NOT EXISTS (SELECT 1 FROM balances) THEN
+        RETURN;
+    END IF;
+    IF to_regclass('balance_decimals') IS NULL THEN
+        RAISE EXCEPTION 'balances can''t be converted without decimals of the chains, list them in balance_decimals table as described in README';
+    END IF;
+
+    -- table is created by operator, so it is queried dynamically to not fail fresh databases
+    EXECUTE 'SELECT string_agg(DISTINCT trim(b.chain_type) || '':'' || trim(b.chain_id), '', '')
+        FROM balances b LEFT JOIN balance_decimals d ON d.chain_type = trim(b.chain_type) AND d.chain_id = trim(b.chain_id)
+        WHERE d.decimals IS NULL' INTO missing;
+    IF missing IS NOT NULL THEN
+        RAISE EXCEPTION 'decimals of chains % are not listed in balance_decimals table', missing;
+    END IF;
+
+    EXECUTE 'UPDATE balances b SET base_amount = round(b.amount * power(10::numeric, d.decimals))
+        FROM balance_decimals d WHERE d.chain_type = trim(b.chain_type) AND d.chain_id = trim(b.chain_id)';
+END
+$$;
+-- +migrate StatementEnd
+
+ALTER TABLE balances DROP COLUMN amount;
+ALTER TABLE balances RENAME COLUMN base_amount TO amount;
+ALTER TABLE balances ALTER COLUMN amount SET NOT NULL;
+
+-- +migrate Down
+-- token balances are left in base units, as their decimals are unknown here
+ALTER TABLE balances ADD COLUMN human_amount decimal;
+
+-- +migrate StatementBegin
+DO $$
+DECLARE
+    missing text;
+BEGIN
+    UPDATE balances SET human_amount = amount WHERE nullif(trim(token_address), '') IS NOT NULL;
+    IF NOT EXISTS (SELECT 1 FROM balances WHERE nullif(trim(token_address), '') IS NULL) THEN
+        RETURN;
+    END IF;
+    IF to_regclass('balance_decimals') IS NULL THEN
+        RAISE EXCEPTION 'balances can''t be converted without decimals of the chains, list them in balance_decimals table as described in README';
+    END IF;
+
+    EXECUTE 'SELECT string_agg(DISTINCT trim(b.chain_type) || '':'' || trim(b.chain_id), '', '')
+        FROM balances b LEFT JOIN balance_decimals d ON d.chain_type = trim(b.chain_type) AND d.chain_id = trim(b.chain_id)
+        WHERE d.decimals IS NULL AND nullif(trim(b.token_address), '''') IS NULL' INTO missing;
+    IF missing IS NOT NULL THEN
+        RAISE EXCEPTION 'decimals of chains % are not listed in balance_decimals table', missing;
+    END IF;
+
+    EXECUTE 'UPDATE balances b SET human_amount = b.amount / power(10::numeric, d.decimals)
+        FROM balance_decimals d WHERE d.chain_type = trim(b.chain_type) AND d.chain_id = trim(b.chain_id)
+        AND nullif(trim(b.token_address), '''') IS NULL';
+END
+$$;
+-- +migrate StatementEnd
+
+ALTER TABLE balances DROP COLUMN amount;
+ALTER TABLE balances RENAME COLUMN human_amount TO amount;
+ALTER TABLE balances ALTER COLUMN amount SET NOT NULL;
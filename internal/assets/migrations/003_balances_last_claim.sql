@@ -0,0 +1,5 @@
+-- +migrate Up
+ALTER TABLE balances ADD COLUMN last_claim_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc');
+
+-- +migrate Down
+ALTER TABLE balances DROP COLUMN last_claim_at;
@@ -0,0 +1,40 @@
+-- +migrate Up
+ALTER TABLE balances
+    ALTER COLUMN user_id TYPE text USING trim(user_id),
+    ALTER COLUMN chain_id TYPE text USING trim(chain_id),
+    ALTER COLUMN chain_type TYPE text USING trim(chain_type),
+    ALTER COLUMN token_address TYPE text USING lower(trim(coalesce(token_address, '')));
+
+ALTER TABLE balances
+    ALTER COLUMN token_address SET DEFAULT '',
+    ALTER COLUMN token_address SET NOT NULL;
+
+-- merging rows duplicated by concurrent sends into the oldest one
+UPDATE balances b
+SET amount = m.amount, last_claim_at = m.last_claim_at
+FROM (
+    SELECT min(id) AS id, sum(amount) AS amount, max(last_claim_at) AS last_claim_at
+    FROM balances
+    GROUP BY user_id, chain_type, chain_id, token_address
+) m
+WHERE b.id = m.id;
+
+DELETE FROM balances
+WHERE id NOT IN (
+    SELECT min(id) FROM balances GROUP BY user_id, chain_type, chain_id, token_address
+);
+
+CREATE UNIQUE INDEX uniq_idx_balances ON balances (user_id, chain_type, chain_id, token_address);
+
+-- +migrate Down
+DROP INDEX uniq_idx_balances;
+
+ALTER TABLE balances
+    ALTER COLUMN token_address DROP NOT NULL,
+    ALTER COLUMN token_address DROP DEFAULT;
+
+ALTER TABLE balances
+    ALTER COLUMN user_id TYPE character(64),
+    ALTER COLUMN chain_id TYPE character(64),
+    ALTER COLUMN chain_type TYPE character(32),
+    ALTER COLUMN token_address TYPE character(64);
@@ -0,0 +1,20 @@
+-- +migrate Up
+CREATE TABLE payouts (
+    id            bigserial primary key,
+    user_id       text NOT NULL,
+    chain_type    text NOT NULL,
+    chain_id      text NOT NULL,
+    token_address text NOT NULL DEFAULT '',
+    receiver      text NOT NULL,
+    amount        numeric(78, 0) NOT NULL,
+    tx_hash       text NOT NULL,
+    status        text NOT NULL,
+    created_at    timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc'),
+    updated_at    timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc')
+);
+
+CREATE INDEX payouts_status_idx ON payouts (status, created_at);
+CREATE INDEX payouts_user_id_idx ON payouts (user_id);
+
+-- +migrate Down
+DROP TABLE payouts;
@@ -0,0 +1,13 @@
+-- +migrate Up
+ALTER TABLE payouts
+    ADD COLUMN idempotency_key text,
+    ADD COLUMN request_hash text;
+
+CREATE UNIQUE INDEX payouts_idempotency_key_idx ON payouts (user_id, idempotency_key) WHERE idempotency_key IS NOT NULL;
+
+-- +migrate Down
+DROP INDEX payouts_idempotency_key_idx;
+
+ALTER TABLE payouts
+    DROP COLUMN idempotency_key,
+    DROP COLUMN request_hash;
@@ -0,0 +1,22 @@
+-- +migrate Up
+CREATE TABLE blocks (
+    kind       text NOT NULL,
+    value      text NOT NULL,
+    reason     text NOT NULL DEFAULT '',
+    created_by text NOT NULL,
+    created_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc'),
+    PRIMARY KEY (kind, value)
+);
+
+ALTER TABLE payouts ADD COLUMN refund_of bigint REFERENCES payouts (id);
+
+CREATE UNIQUE INDEX payouts_refund_of_idx ON payouts (refund_of) WHERE refund_of IS NOT NULL;
+CREATE INDEX payouts_receiver_idx ON payouts (lower(receiver));
+
+-- +migrate Down
+DROP INDEX payouts_receiver_idx;
+DROP INDEX payouts_refund_of_idx;
+
+ALTER TABLE payouts DROP COLUMN refund_of;
+
+DROP TABLE blocks;
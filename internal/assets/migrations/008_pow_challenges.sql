@@ -0,0 +1,12 @@
+-- +migrate Up
+CREATE TABLE pow_challenges (
+    id         text primary key,
+    expires_at timestamp without time zone NOT NULL
+);
+
+CREATE INDEX pow_challenges_expires_at_idx ON pow_challenges (expires_at);
+CREATE INDEX payouts_created_at_idx ON payouts (created_at);
+
+-- +migrate Down
+DROP INDEX payouts_created_at_idx;
+DROP TABLE pow_challenges;
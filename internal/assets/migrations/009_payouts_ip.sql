@@ -0,0 +1,8 @@
+-- +migrate Up
+ALTER TABLE payouts ADD COLUMN ip inet;
+
+CREATE INDEX payouts_ip_idx ON payouts (ip);
+
+-- +migrate Down
+DROP INDEX payouts_ip_idx;
+ALTER TABLE payouts DROP COLUMN ip;
@@ -0,0 +1,13 @@
+-- +migrate Up
+CREATE TABLE addresses (
+    chain_type  text NOT NULL,
+    address     text NOT NULL,
+    user_id     text NOT NULL,
+    verified_at timestamp without time zone NOT NULL DEFAULT (now() at time zone 'utc'),
+    PRIMARY KEY (chain_type, address)
+);
+
+CREATE INDEX addresses_user_id_idx ON addresses (user_id);
+
+-- +migrate Down
+DROP TABLE addresses;
@@ -0,0 +1,6 @@
+-- +migrate Up
+-- receivers of non-evm chains are case-sensitive and matched exactly
+CREATE INDEX payouts_receiver_exact_idx ON payouts (receiver) WHERE chain_type <> 'evm';
+
+-- +migrate Down
+DROP INDEX payouts_receiver_exact_idx;
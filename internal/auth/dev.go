@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"faucet-svc/resources"
+	"net/http"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// DevIssuer marks development tokens, so they are never mixed up with real ones
+const DevIssuer = "faucet-svc-dev"
+
+// Dev issues and verifies tokens of named test users, it must be used for local development only
+type Dev struct {
+	secret   []byte
+	users    map[string][]string
+	verifier Authenticator
+}
+
+// NewDev creates dev authenticator, users maps test user name to its roles
+func NewDev(secret string, users map[string][]string) *Dev {
+	return &Dev{
+		secret: []byte(secret),
+		users:  users,
+		verifier: NewJWT(JWTOpts{
+			Secrets:     []string{secret},
+			Issuer:      DevIssuer,
+			UserIDClaim: "sub",
+			RolesClaim:  "roles",
+		}),
+	}
+}
+
+// Issue signs token for configured test user
+func (d *Dev) Issue(name string, ttl time.Duration) (string, error) {
+	roles, ok := d.users[name]
+	if !ok {
+		return "", errors.From(errors.New("unknown dev user"), logan.F{"user": name})
+	}
+
+	now := time.Now()
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":   DevIssuer,
+		"sub":   name,
+		"roles": roles,
+		"iat":   now.Unix(),
+		"exp":   now.Add(ttl).Unix(),
+	}).SignedString(d.secret)
+	return token, errors.Wrap(err, "failed to sign dev token")
+}
+
+func (d *Dev) Authenticate(r *http.Request) (*resources.User, error) {
+	user, err := d.verifier.Authenticate(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// roles are taken from config, so removed users and revoked roles take effect without reissuing
+	roles, ok := d.users[user.Id]
+	if !ok {
+		return nil, errors.Wrap(ErrUnauthorized, "unknown dev user")
+	}
+	user.Attributes = &resources.UserAttributes{Roles: roles}
+	return user, nil
+}
@@ -0,0 +1,192 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// minForcedRefresh limits refetches caused by tokens with unknown key id
+const minForcedRefresh = time.Minute
+
+// ErrKeyNotFound is returned when key set has no key with requested id even after reload
+var ErrKeyNotFound = errors.New("key not found")
+
+// JWKS is a key set loaded from url or file, keys are cached and reloaded
+// once refresh interval passes or token with unknown key id arrives
+type JWKS struct {
+	url     string
+	file    string
+	refresh time.Duration
+	client  *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+func NewJWKS(url, file string, refresh time.Duration, client *http.Client) *JWKS {
+	return &JWKS{
+		url:     url,
+		file:    file,
+		refresh: refresh,
+		client:  client,
+	}
+}
+
+// Key returns public key by its id, empty id is allowed when the set contains the only key
+func (s *JWKS) Key(kid string) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys == nil || time.Since(s.fetchedAt) > s.refresh {
+		if err := s.load(); err != nil {
+			return nil, errors.Wrap(err, "failed to load jwks")
+		}
+	}
+
+	key, ok := s.find(kid)
+	if ok {
+		return key, nil
+	}
+
+	if time.Since(s.fetchedAt) > minForcedRefresh {
+		if err := s.load(); err != nil {
+			return nil, errors.Wrap(err, "failed to reload jwks")
+		}
+		if key, ok = s.find(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, errors.From(ErrKeyNotFound, logan.F{"kid": kid})
+}
+
+func (s *JWKS) find(kid string) (interface{}, bool) {
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *JWKS) load() error {
+	raw, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return errors.Wrap(err, "failed to unmarshal jwks")
+	}
+
+	keys := make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := k.publicKey()
+		if err != nil {
+			return errors.Wrap(err, "failed to parse jwk", logan.F{"kid": k.Kid})
+		}
+		keys[k.Kid] = key
+	}
+
+	s.keys = keys
+	s.fetchedAt = time.Now()
+	return nil
+}
+
+func (s *JWKS) read() ([]byte, error) {
+	if s.file != "" {
+		raw, err := os.ReadFile(s.file)
+		return raw, errors.Wrap(err, "failed to read jwks file")
+	}
+
+	resp, err := s.client.Get(s.url)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch jwks")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.From(errors.New("unexpected jwks response status"), logan.F{
+			"status": resp.StatusCode,
+		})
+	}
+
+	raw, err := io.ReadAll(resp.Body)
+	return raw, errors.Wrap(err, "failed to read jwks response")
+}
+
+type jwk struct {
+	Kid string `json:"kid"`
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	// RSA
+	N string `json:"n"`
+	E string `json:"e"`
+	// EC
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jwk) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeInt(k.N)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid modulus")
+		}
+		e, err := decodeInt(k.E)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, errors.From(errors.New("unsupported curve"), logan.F{"crv": k.Crv})
+		}
+		x, err := decodeInt(k.X)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid x coordinate")
+		}
+		y, err := decodeInt(k.Y)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid y coordinate")
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return nil, errors.New("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+	default:
+		return nil, errors.From(errors.New("unsupported key type"), logan.F{"kty": k.Kty})
+	}
+}
+
+func decodeInt(value string) (*big.Int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(raw), nil
+}
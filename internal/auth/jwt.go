@@ -0,0 +1,157 @@
+package auth
+
+import (
+	"faucet-svc/resources"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type JWTOpts struct {
+	// Secrets are HS256 keys, all of them are tried, so secrets can be rotated
+	Secrets []string
+	// JWKS provides RS256 and ES256 public keys, nil disables asymmetric algorithms
+	JWKS *JWKS
+	// Issuer and Audience are checked only if set
+	Issuer   string
+	Audience string
+	// UserIDClaim and RolesClaim are names of claims holding user id and roles
+	UserIDClaim string
+	RolesClaim  string
+}
+
+type jwtAuthenticator struct {
+	opts   JWTOpts
+	parser *jwt.Parser
+}
+
+// NewJWT creates authenticator verifying bearer tokens locally
+func NewJWT(opts JWTOpts) Authenticator {
+	var methods []string
+	if len(opts.Secrets) != 0 {
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if opts.JWKS != nil {
+		methods = append(methods, jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg())
+	}
+
+	return &jwtAuthenticator{
+		opts:   opts,
+		parser: jwt.NewParser(jwt.WithValidMethods(methods)),
+	}
+}
+
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (*resources.User, error) {
+	raw, ok := BearerToken(r)
+	if !ok {
+		return nil, errors.Wrap(ErrUnauthorized, "bearer token is missing")
+	}
+
+	claims, err := a.verify(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, ok := claimString(claims[a.opts.UserIDClaim])
+	if !ok {
+		return nil, errors.Wrap(ErrUnauthorized, "token has no user id")
+	}
+
+	return &resources.User{
+		Id:   userId,
+		Type: "user",
+		Attributes: &resources.UserAttributes{
+			Roles: claimStrings(claims[a.opts.RolesClaim]),
+		},
+	}, nil
+}
+
+func (a *jwtAuthenticator) verify(raw string) (jwt.MapClaims, error) {
+	var lastErr error
+	// each HS256 secret is tried in turn, tokens signed with asymmetric keys are parsed once
+	for i := 0; i == 0 || i < len(a.opts.Secrets); i++ {
+		var keyErr error
+		claims := jwt.MapClaims{}
+		token, err := a.parser.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+			key, err := a.key(token, i)
+			if err != nil && errors.Cause(err) != ErrKeyNotFound {
+				keyErr = err
+			}
+			return key, err
+		})
+		if keyErr != nil {
+			return nil, errors.Wrap(keyErr, "failed to get verification key")
+		}
+		if err == nil {
+			return claims, a.verifyClaims(claims)
+		}
+
+		lastErr = err
+
+		vErr, ok := err.(*jwt.ValidationError)
+		if !ok || vErr.Errors&jwt.ValidationErrorSignatureInvalid == 0 || token.Method != jwt.SigningMethodHS256 {
+			break
+		}
+	}
+	return nil, errors.Wrap(ErrUnauthorized, lastErr.Error())
+}
+
+func (a *jwtAuthenticator) key(token *jwt.Token, secret int) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return []byte(a.opts.Secrets[secret]), nil
+	case jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		return a.opts.JWKS.Key(kid)
+	default:
+		// unreachable, parser accepts only configured methods
+		return nil, errors.New("unexpected signing method")
+	}
+}
+
+func (a *jwtAuthenticator) verifyClaims(claims jwt.MapClaims) error {
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return errors.Wrap(ErrUnauthorized, "token has no expiration time")
+	}
+	if a.opts.Issuer != "" && !claims.VerifyIssuer(a.opts.Issuer, true) {
+		return errors.Wrap(ErrUnauthorized, "unexpected token issuer")
+	}
+	if a.opts.Audience != "" && !claims.VerifyAudience(a.opts.Audience, true) {
+		return errors.Wrap(ErrUnauthorized, "unexpected token audience")
+	}
+	return nil
+}
+
+func claimString(value interface{}) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, v != ""
+	case float64:
+		// numeric ids are decoded from json as float64
+		return fmt.Sprintf("%.0f", v), true
+	default:
+		return "", false
+	}
+}
+
+// claimStrings accepts both json array and space separated string, as used by scope-like claims
+func claimStrings(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		result := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,270 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func TestJWTAuthenticate(t *testing.T) {
+	rsaKey := mustRSAKey(t)
+	otherRSAKey := mustRSAKey(t)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate ec key: %v", err)
+	}
+	jwks := NewJWKS("", writeJWKS(t, map[string]interface{}{
+		"rsa": &rsaKey.PublicKey,
+		"ec":  &ecKey.PublicKey,
+	}), time.Hour, nil)
+
+	valid := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "user-1",
+			"roles": []interface{}{"tester"},
+			"iss":   "issuer",
+			"aud":   "faucet",
+			"exp":   time.Now().Add(time.Hour).Unix(),
+		}
+	}
+	with := func(key string, value interface{}) jwt.MapClaims {
+		claims := valid()
+		if value == nil {
+			delete(claims, key)
+		} else {
+			claims[key] = value
+		}
+		return claims
+	}
+
+	cases := []struct {
+		name      string
+		opts      JWTOpts
+		token     string
+		wantUser  string
+		wantRoles []string
+		wantErr   bool
+	}{
+		{
+			name:      "hs256",
+			opts:      JWTOpts{Secrets: []string{"secret"}},
+			token:     signHS256(t, "secret", valid()),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:      "hs256 rotated secret",
+			opts:      JWTOpts{Secrets: []string{"new", "old"}},
+			token:     signHS256(t, "old", valid()),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:    "hs256 wrong secret",
+			opts:    JWTOpts{Secrets: []string{"secret", "other"}},
+			token:   signHS256(t, "attacker", valid()),
+			wantErr: true,
+		},
+		{
+			name:      "rs256",
+			opts:      JWTOpts{JWKS: jwks},
+			token:     sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, valid()),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:      "es256",
+			opts:      JWTOpts{JWKS: jwks},
+			token:     sign(t, jwt.SigningMethodES256, "ec", ecKey, valid()),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:    "rs256 signed by another key",
+			opts:    JWTOpts{JWKS: jwks},
+			token:   sign(t, jwt.SigningMethodRS256, "rsa", otherRSAKey, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 unknown key id",
+			opts:    JWTOpts{JWKS: jwks},
+			token:   sign(t, jwt.SigningMethodRS256, "unknown", rsaKey, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "rs256 without jwks",
+			opts:    JWTOpts{Secrets: []string{"secret"}},
+			token:   sign(t, jwt.SigningMethodRS256, "rsa", rsaKey, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "hs256 without secrets",
+			opts:    JWTOpts{JWKS: jwks},
+			token:   signHS256(t, "secret", valid()),
+			wantErr: true,
+		},
+		{
+			name:    "hs512",
+			opts:    JWTOpts{Secrets: []string{"secret"}},
+			token:   sign(t, jwt.SigningMethodHS512, "", []byte("secret"), valid()),
+			wantErr: true,
+		},
+		{
+			name:    "none algorithm",
+			opts:    JWTOpts{Secrets: []string{"secret"}, JWKS: jwks},
+			token:   sign(t, jwt.SigningMethodNone, "", jwt.UnsafeAllowNoneSignatureType, valid()),
+			wantErr: true,
+		},
+		{
+			name:    "expired",
+			opts:    JWTOpts{Secrets: []string{"secret"}},
+			token:   signHS256(t, "secret", with("exp", time.Now().Add(-time.Minute).Unix())),
+			wantErr: true,
+		},
+		{
+			name:    "no expiration",
+			opts:    JWTOpts{Secrets: []string{"secret"}},
+			token:   signHS256(t, "secret", with("exp", nil)),
+			wantErr: true,
+		},
+		{
+			name:    "wrong issuer",
+			opts:    JWTOpts{Secrets: []string{"secret"}, Issuer: "issuer"},
+			token:   signHS256(t, "secret", with("iss", "other")),
+			wantErr: true,
+		},
+		{
+			name:      "expected issuer",
+			opts:      JWTOpts{Secrets: []string{"secret"}, Issuer: "issuer"},
+			token:     signHS256(t, "secret", valid()),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:    "wrong audience",
+			opts:    JWTOpts{Secrets: []string{"secret"}, Audience: "faucet"},
+			token:   signHS256(t, "secret", with("aud", "other")),
+			wantErr: true,
+		},
+		{
+			name:    "no user id",
+			opts:    JWTOpts{Secrets: []string{"secret"}},
+			token:   signHS256(t, "secret", with("sub", nil)),
+			wantErr: true,
+		},
+		{
+			name:      "numeric user id",
+			opts:      JWTOpts{Secrets: []string{"secret"}},
+			token:     signHS256(t, "secret", with("sub", 42)),
+			wantUser:  "42",
+			wantRoles: []string{"tester"},
+		},
+		{
+			name:      "space separated roles",
+			opts:      JWTOpts{Secrets: []string{"secret"}},
+			token:     signHS256(t, "secret", with("roles", "tester admin")),
+			wantUser:  "user-1",
+			wantRoles: []string{"tester", "admin"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.opts.UserIDClaim = "sub"
+			tc.opts.RolesClaim = "roles"
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r.Header.Set("Authorization", "Bearer "+tc.token)
+
+			user, err := NewJWT(tc.opts).Authenticate(r)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Authenticate() = %s, want error", user.Id)
+				}
+				if errors.Cause(err) != ErrUnauthorized {
+					t.Errorf("Authenticate() error = %v, want unauthorized", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Authenticate() failed: %v", err)
+			}
+			if user.Id != tc.wantUser {
+				t.Errorf("Authenticate() user = %s, want %s", user.Id, tc.wantUser)
+			}
+			if !reflect.DeepEqual(user.Attributes.Roles, tc.wantRoles) {
+				t.Errorf("Authenticate() roles = %v, want %v", user.Attributes.Roles, tc.wantRoles)
+			}
+		})
+	}
+}
+
+func mustRSAKey(t *testing.T) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate rsa key: %v", err)
+	}
+	return key
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	return sign(t, jwt.SigningMethodHS256, "", []byte(secret), claims)
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("failed to sign token: %v", err)
+	}
+	return signed
+}
+
+// writeJWKS writes public keys by their ids to a key set file
+func writeJWKS(t *testing.T, keys map[string]interface{}) string {
+	encode := func(value *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(value.Bytes())
+	}
+
+	set := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	for kid, key := range keys {
+		switch key := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kid: kid, Kty: "RSA", Use: "sig", N: encode(key.N), E: encode(big.NewInt(int64(key.E))),
+			})
+		case *ecdsa.PublicKey:
+			set.Keys = append(set.Keys, jwk{
+				Kid: kid, Kty: "EC", Use: "sig", Crv: "P-256", X: encode(key.X), Y: encode(key.Y),
+			})
+		}
+	}
+
+	raw, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("failed to marshal jwks: %v", err)
+	}
+	file := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(file, raw, 0600); err != nil {
+		t.Fatalf("failed to write jwks: %v", err)
+	}
+	return file
+}
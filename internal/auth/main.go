@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"faucet-svc/resources"
+	"net/http"
+	"strings"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// ErrUnauthorized is returned when request credentials are missing or invalid,
+// any other error means that authentication could not be performed at all
+var ErrUnauthorized = errors.New("unauthorized")
+
+type Authenticator interface {
+	Authenticate(r *http.Request) (*resources.User, error)
+}
+
+// WithFallback tries primary authenticator first and uses fallback only for requests
+// rejected by primary one, e.g. tokens signed by keys unknown to local verifier
+func WithFallback(primary, fallback Authenticator) Authenticator {
+	return &fallbackAuthenticator{
+		primary:  primary,
+		fallback: fallback,
+	}
+}
+
+type fallbackAuthenticator struct {
+	primary  Authenticator
+	fallback Authenticator
+}
+
+func (a *fallbackAuthenticator) Authenticate(r *http.Request) (*resources.User, error) {
+	user, err := a.primary.Authenticate(r)
+	if err == nil {
+		return user, nil
+	}
+	if errors.Cause(err) != ErrUnauthorized {
+		return nil, err
+	}
+
+	user, fallbackErr := a.fallback.Authenticate(r)
+	if fallbackErr != nil {
+		return nil, errors.Wrap(fallbackErr, "fallback authentication failed", logan.F{
+			"primary_error": err.Error(),
+		})
+	}
+	return user, nil
+}
+
+// BearerToken extracts token from Authorization header
+func BearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if len(header) < len("Bearer ") || !strings.EqualFold(header[:len("Bearer ")], "Bearer ") {
+		return "", false
+	}
+
+	token := strings.TrimSpace(header[len("Bearer "):])
+	return token, token != ""
+}
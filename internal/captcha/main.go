@@ -0,0 +1,35 @@
+package captcha
+
+import (
+	"context"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	ProviderHCaptcha  = "hcaptcha"
+	ProviderReCaptcha = "recaptcha"
+	ProviderTurnstile = "turnstile"
+	ProviderStub      = "stub"
+)
+
+// ErrInvalid is returned when captcha solution is rejected, other errors mean that it could not be checked
+var ErrInvalid = errors.New("invalid captcha")
+
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// DefaultVerifyURL returns siteverify endpoint of the provider, empty for unknown ones
+func DefaultVerifyURL(provider string) string {
+	switch provider {
+	case ProviderHCaptcha:
+		return "https://api.hcaptcha.com/siteverify"
+	case ProviderReCaptcha:
+		return "https://www.google.com/recaptcha/api/siteverify"
+	case ProviderTurnstile:
+		return "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,72 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type siteVerifier struct {
+	verifyURL string
+	secret    string
+	client    *http.Client
+}
+
+// NewSiteVerifier creates verifier of the siteverify protocol shared by hCaptcha, reCAPTCHA and Turnstile
+func NewSiteVerifier(verifyURL, secret string, client *http.Client) Verifier {
+	return &siteVerifier{
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    client,
+	}
+}
+
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+func (v *siteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return errors.Wrap(err, "failed to create siteverify request")
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to do siteverify request")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.From(errors.New("unexpected siteverify response status"), logan.F{
+			"status": resp.StatusCode,
+		})
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return errors.Wrap(err, "failed to decode siteverify response")
+	}
+
+	if !result.Success {
+		return errors.From(ErrInvalid, logan.F{
+			"error_codes": strings.Join(result.ErrorCodes, ","),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"faucet-svc/internal/config"
+	"fmt"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// IssueDevToken prints signed token of configured test user, works only with dev auth mode
+func IssueDevToken(cfg config.Config, user string, ttl time.Duration) error {
+	dev := cfg.DevAuth()
+	if dev == nil {
+		return errors.New("dev auth mode is not enabled")
+	}
+
+	if ttl == 0 {
+		ttl = cfg.AuthConfig().Dev.TokenTTL
+	}
+
+	token, err := dev.Issue(user, ttl)
+	if err != nil {
+		return errors.Wrap(err, "failed to issue dev token")
+	}
+
+	fmt.Println(token)
+	return nil
+}
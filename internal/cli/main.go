@@ -30,6 +30,11 @@ func Run(args []string) bool {
 	migrateUpCmd := migrateCmd.Command("up", "migrate db up")
 	migrateDownCmd := migrateCmd.Command("down", "migrate db down")
 
+	devCmd := app.Command("dev", "development helpers")
+	devTokenCmd := devCmd.Command("token", "issue token of dev auth test user")
+	devTokenUser := devTokenCmd.Arg("user", "test user name from auth.dev.users").Required().String()
+	devTokenTTL := devTokenCmd.Flag("ttl", "token lifetime, auth.dev.token_ttl by default").Duration()
+
 	// custom commands go here...
 
 	cmd, err := app.Parse(args[1:])
@@ -45,6 +50,8 @@ func Run(args []string) bool {
 		err = MigrateUp(cfg)
 	case migrateDownCmd.FullCommand():
 		err = MigrateDown(cfg)
+	case devTokenCmd.FullCommand():
+		err = IssueDevToken(cfg, *devTokenUser, *devTokenTTL)
 	// handle any custom commands here in the same way
 	default:
 		log.Errorf("unknown command %s", cmd)
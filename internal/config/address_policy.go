@@ -0,0 +1,85 @@
+package config
+
+import (
+	"faucet-svc/internal/types"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type AddressPolicier interface {
+	AddressPolicy() types.AddressPolicy
+}
+
+type addressPolicier struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewAddressPolicier(getter kv.Getter) AddressPolicier {
+	return &addressPolicier{getter: getter}
+}
+
+type deniedAddress struct {
+	Address string `fig:"address,required"`
+	Reason  string `fig:"reason"`
+}
+
+func (c *addressPolicier) AddressPolicy() types.AddressPolicy {
+	return c.once.Do(func() interface{} {
+		cfg := struct {
+			Allow     []string        `fig:"allow"`
+			Deny      []deniedAddress `fig:"deny"`
+			Cooldown  time.Duration   `fig:"cooldown"`
+			Funneling struct {
+				MaxUsers uint64        `fig:"max_users"`
+				Window   time.Duration `fig:"window"`
+				Block    bool          `fig:"block"`
+			} `fig:"funneling"`
+		}{}
+		cfg.Funneling.Window = 24 * time.Hour
+
+		raw, err := c.getter.GetStringMap("address_policy")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get address_policy config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out address_policy"))
+		}
+
+		if cfg.Funneling.MaxUsers != 0 && cfg.Funneling.Window <= 0 {
+			panic(errors.New("address_policy funneling window must be positive"))
+		}
+
+		policy := types.AddressPolicy{
+			Allow:    make(map[string]struct{}, len(cfg.Allow)),
+			Deny:     make(map[string]string, len(cfg.Deny)),
+			Cooldown: cfg.Cooldown,
+			Funneling: types.Funneling{
+				MaxUsers: cfg.Funneling.MaxUsers,
+				Window:   cfg.Funneling.Window,
+				Block:    cfg.Funneling.Block,
+			},
+		}
+		for _, address := range cfg.Allow {
+			policy.Allow[strings.ToLower(address)] = struct{}{}
+		}
+		for _, denied := range cfg.Deny {
+			address := strings.ToLower(denied.Address)
+			if _, ok := policy.Allow[address]; ok {
+				panic(errors.Errorf("address %s is both allowed and denied", denied.Address))
+			}
+			policy.Deny[address] = denied.Reason
+		}
+		return policy
+	}).(types.AddressPolicy)
+}
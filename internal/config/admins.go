@@ -0,0 +1,56 @@
+package config
+
+import (
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type Adminer interface {
+	Admins() Admins
+}
+
+// Admins is a set of user ids allowed to use admin endpoints
+type Admins map[string]struct{}
+
+func (a Admins) Contains(userId string) bool {
+	_, ok := a[userId]
+	return ok
+}
+
+type adminer struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewAdminer(getter kv.Getter) Adminer {
+	return &adminer{getter: getter}
+}
+
+func (c *adminer) Admins() Admins {
+	return c.once.Do(func() interface{} {
+		var cfg struct {
+			UserIDs []string `fig:"user_ids"`
+		}
+
+		raw, err := c.getter.GetStringMap("admin")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get admin config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out admin"))
+		}
+
+		admins := Admins{}
+		for _, id := range cfg.UserIDs {
+			admins[id] = struct{}{}
+		}
+		return admins
+	}).(Admins)
+}
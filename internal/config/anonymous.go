@@ -0,0 +1,169 @@
+package config
+
+import (
+	"faucet-svc/internal/captcha"
+	"faucet-svc/internal/pow"
+	"faucet-svc/internal/types"
+	"net/http"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type Anonymouser interface {
+	Anonymous() AnonymousConfig
+}
+
+// AnonymousConfig allows sends without account, per ip limits are quotas of anonymous role in limits config
+type AnonymousConfig struct {
+	Enabled bool
+	// Captcha is nil if captcha is not configured
+	Captcha captcha.Verifier
+	// Pow is nil if proof of work challenges are disabled
+	Pow *PowConfig
+	// AddressQuotas limit how much a single receiver address can get anonymously
+	AddressQuotas types.Quotas
+}
+
+type PowConfig struct {
+	Issuer     *pow.Issuer
+	Difficulty pow.Difficulty
+	// Window is the period recent payouts are counted for to scale difficulty
+	Window time.Duration
+}
+
+type captchaConfig struct {
+	Provider  string        `fig:"provider"`
+	Secret    string        `fig:"secret"`
+	VerifyURL string        `fig:"verify_url"`
+	StubToken string        `fig:"stub_token"`
+	Timeout   time.Duration `fig:"timeout"`
+}
+
+type powConfig struct {
+	Enabled bool   `fig:"enabled"`
+	Secret  string `fig:"secret"`
+	// TTL is how long issued challenge can be solved
+	TTL            time.Duration `fig:"ttl"`
+	BaseDifficulty uint8         `fig:"base_difficulty"`
+	MaxDifficulty  uint8         `fig:"max_difficulty"`
+	DemandStep     uint64        `fig:"demand_step"`
+	DemandWindow   time.Duration `fig:"demand_window"`
+}
+
+// minPowSecretLen guards challenges from being signed with placeholder secrets
+const minPowSecretLen = 32
+
+type anonymouser struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewAnonymouser(getter kv.Getter) Anonymouser {
+	return &anonymouser{getter: getter}
+}
+
+func (c *anonymouser) Anonymous() AnonymousConfig {
+	return c.once.Do(func() interface{} {
+		cfg := struct {
+			Enabled       bool          `fig:"enabled"`
+			Captcha       captchaConfig `fig:"captcha"`
+			Pow           powConfig     `fig:"pow"`
+			AddressQuotas []quota       `fig:"address_quotas"`
+		}{
+			Captcha: captchaConfig{
+				Timeout: 10 * time.Second,
+			},
+			Pow: powConfig{
+				TTL:            5 * time.Minute,
+				BaseDifficulty: 18,
+				MaxDifficulty:  26,
+				DemandStep:     20,
+				DemandWindow:   10 * time.Minute,
+			},
+		}
+
+		raw, err := c.getter.GetStringMap("anonymous")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get anonymous config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out anonymous"))
+		}
+
+		if !cfg.Enabled {
+			return AnonymousConfig{}
+		}
+
+		result := AnonymousConfig{
+			Enabled:       true,
+			AddressQuotas: newQuotas(cfg.AddressQuotas),
+		}
+		if cfg.Captcha.Provider != "" {
+			result.Captcha = newCaptchaVerifier(cfg.Captcha)
+		}
+		if cfg.Pow.Enabled {
+			result.Pow = newPowConfig(cfg.Pow)
+		}
+		if result.Captcha == nil && result.Pow == nil {
+			panic(errors.New("anonymous mode requires captcha provider or pow to be enabled"))
+		}
+		return result
+	}).(AnonymousConfig)
+}
+
+func newCaptchaVerifier(cfg captchaConfig) captcha.Verifier {
+	switch cfg.Provider {
+	case captcha.ProviderStub:
+		if cfg.StubToken == "" {
+			panic(errors.New("anonymous captcha stub_token must be set for stub provider"))
+		}
+		return captcha.NewStubVerifier(cfg.StubToken)
+	case captcha.ProviderHCaptcha, captcha.ProviderReCaptcha, captcha.ProviderTurnstile:
+		if cfg.Secret == "" {
+			panic(errors.Errorf("anonymous captcha secret must be set for %s provider", cfg.Provider))
+		}
+		verifyURL := cfg.VerifyURL
+		if verifyURL == "" {
+			verifyURL = captcha.DefaultVerifyURL(cfg.Provider)
+		}
+		return captcha.NewSiteVerifier(verifyURL, cfg.Secret, &http.Client{
+			Timeout: cfg.Timeout,
+		})
+	default:
+		panic(errors.Errorf("unknown captcha provider %s", cfg.Provider))
+	}
+}
+
+func newPowConfig(cfg powConfig) *PowConfig {
+	if len(cfg.Secret) < minPowSecretLen {
+		panic(errors.Errorf("anonymous pow secret must be at least %d characters long", minPowSecretLen))
+	}
+	if cfg.TTL <= 0 || cfg.DemandWindow <= 0 {
+		panic(errors.New("anonymous pow ttl and demand_window must be positive"))
+	}
+	if cfg.BaseDifficulty == 0 || cfg.MaxDifficulty < cfg.BaseDifficulty || cfg.MaxDifficulty > 64 {
+		panic(errors.New("anonymous pow difficulty must satisfy 0 < base_difficulty <= max_difficulty <= 64"))
+	}
+	if cfg.DemandStep == 0 {
+		panic(errors.New("anonymous pow demand_step must be positive"))
+	}
+
+	return &PowConfig{
+		Issuer: pow.NewIssuer(cfg.Secret, cfg.TTL),
+		Difficulty: pow.Difficulty{
+			Base: cfg.BaseDifficulty,
+			Max:  cfg.MaxDifficulty,
+			Step: cfg.DemandStep,
+		},
+		Window: cfg.DemandWindow,
+	}
+}
@@ -0,0 +1,172 @@
+package config
+
+import (
+	"faucet-svc/internal/auth"
+	"net/http"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+const (
+	AuthModeDoorman = "doorman"
+	AuthModeJWT     = "jwt"
+	AuthModeDev     = "dev"
+)
+
+// minDevSecretLen guards dev mode from being enabled with placeholder secrets
+const minDevSecretLen = 32
+
+type Auther interface {
+	AuthConfig() AuthConfig
+	Authenticator() auth.Authenticator
+	// DevAuth returns nil unless dev mode is enabled
+	DevAuth() *auth.Dev
+}
+
+type AuthConfig struct {
+	// Mode is doorman (every request is checked by doorman service), jwt (tokens are verified locally)
+	// or dev (tokens of test users are issued by cli command)
+	Mode string `fig:"mode"`
+	// DoormanFallback makes jwt mode ask doorman about tokens rejected locally
+	DoormanFallback bool      `fig:"doorman_fallback"`
+	JWT             JWTConfig `fig:"jwt"`
+	Dev             DevConfig `fig:"dev"`
+}
+
+type JWTConfig struct {
+	HS256Secrets []string `fig:"hs256_secrets"`
+	// JWKSUrl or JWKSFile is a source of RS256/ES256 public keys
+	JWKSUrl     string        `fig:"jwks_url"`
+	JWKSFile    string        `fig:"jwks_file"`
+	JWKSRefresh time.Duration `fig:"jwks_refresh"`
+	Issuer      string        `fig:"issuer"`
+	Audience    string        `fig:"audience"`
+	UserIDClaim string        `fig:"user_id_claim"`
+	RolesClaim  string        `fig:"roles_claim"`
+}
+
+type DevConfig struct {
+	Secret   string        `fig:"secret"`
+	TokenTTL time.Duration `fig:"token_ttl"`
+	// Users maps test user name to its roles
+	Users map[string][]string `fig:"users"`
+}
+
+type auther struct {
+	once          comfig.Once
+	authenticator comfig.Once
+	dev           comfig.Once
+	getter        kv.Getter
+	doorman       DoormanConfiger
+}
+
+func NewAuther(getter kv.Getter, doorman DoormanConfiger) Auther {
+	return &auther{
+		getter:  getter,
+		doorman: doorman,
+	}
+}
+
+func (c *auther) AuthConfig() AuthConfig {
+	return c.once.Do(func() interface{} {
+		cfg := AuthConfig{
+			Mode: AuthModeDoorman,
+			JWT: JWTConfig{
+				JWKSRefresh: time.Hour,
+				UserIDClaim: "sub",
+				RolesClaim:  "roles",
+			},
+			Dev: DevConfig{
+				TokenTTL: 24 * time.Hour,
+			},
+		}
+
+		raw, err := c.getter.GetStringMap("auth")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get auth config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out auth"))
+		}
+
+		switch cfg.Mode {
+		case AuthModeDoorman:
+		case AuthModeJWT:
+			jwt := cfg.JWT
+			if len(jwt.HS256Secrets) == 0 && jwt.JWKSUrl == "" && jwt.JWKSFile == "" {
+				panic(errors.New("auth jwt requires hs256_secrets, jwks_url or jwks_file"))
+			}
+			if jwt.JWKSUrl != "" && jwt.JWKSFile != "" {
+				panic(errors.New("auth jwt jwks_url and jwks_file are mutually exclusive"))
+			}
+			if jwt.JWKSRefresh <= 0 {
+				panic(errors.New("auth jwt jwks_refresh must be positive"))
+			}
+		case AuthModeDev:
+			if len(cfg.Dev.Secret) < minDevSecretLen {
+				panic(errors.Errorf("auth dev secret must be at least %d characters long", minDevSecretLen))
+			}
+			if len(cfg.Dev.Users) == 0 {
+				panic(errors.New("auth dev users must not be empty"))
+			}
+			if cfg.Dev.TokenTTL <= 0 {
+				panic(errors.New("auth dev token_ttl must be positive"))
+			}
+		default:
+			panic(errors.Errorf("unknown auth mode %s", cfg.Mode))
+		}
+		return cfg
+	}).(AuthConfig)
+}
+
+func (c *auther) Authenticator() auth.Authenticator {
+	return c.authenticator.Do(func() interface{} {
+		cfg := c.AuthConfig()
+		switch cfg.Mode {
+		case AuthModeDoorman:
+			connector := c.doorman.DoormanConnector()
+			return &connector
+		case AuthModeDev:
+			return c.DevAuth()
+		}
+
+		opts := auth.JWTOpts{
+			Secrets:     cfg.JWT.HS256Secrets,
+			Issuer:      cfg.JWT.Issuer,
+			Audience:    cfg.JWT.Audience,
+			UserIDClaim: cfg.JWT.UserIDClaim,
+			RolesClaim:  cfg.JWT.RolesClaim,
+		}
+		if cfg.JWT.JWKSUrl != "" || cfg.JWT.JWKSFile != "" {
+			opts.JWKS = auth.NewJWKS(cfg.JWT.JWKSUrl, cfg.JWT.JWKSFile, cfg.JWT.JWKSRefresh, &http.Client{
+				Timeout: 15 * time.Second,
+			})
+		}
+
+		authenticator := auth.NewJWT(opts)
+		if cfg.DoormanFallback {
+			connector := c.doorman.DoormanConnector()
+			authenticator = auth.WithFallback(authenticator, &connector)
+		}
+		return authenticator
+	}).(auth.Authenticator)
+}
+
+func (c *auther) DevAuth() *auth.Dev {
+	return c.dev.Do(func() interface{} {
+		cfg := c.AuthConfig()
+		if cfg.Mode != AuthModeDev {
+			return (*auth.Dev)(nil)
+		}
+		return auth.NewDev(cfg.Dev.Secret, cfg.Dev.Users)
+	}).(*auth.Dev)
+}
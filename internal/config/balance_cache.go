@@ -0,0 +1,63 @@
+package config
+
+import (
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type BalanceCacher interface {
+	BalanceCache() BalanceCacheConfig
+}
+
+type BalanceCacheConfig struct {
+	// RefreshInterval is how often faucet balances are re-read from chain rpc
+	RefreshInterval time.Duration `fig:"refresh_interval"`
+	// StaleAfter is the age after which a cached balance is reported as stale
+	StaleAfter time.Duration `fig:"stale_after"`
+	// Concurrency limits the number of simultaneous rpc balance calls
+	Concurrency int `fig:"concurrency"`
+}
+
+type balanceCacher struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewBalanceCacher(getter kv.Getter) BalanceCacher {
+	return &balanceCacher{getter: getter}
+}
+
+func (c *balanceCacher) BalanceCache() BalanceCacheConfig {
+	return c.once.Do(func() interface{} {
+		cfg := BalanceCacheConfig{
+			RefreshInterval: 30 * time.Second,
+			StaleAfter:      2 * time.Minute,
+			Concurrency:     8,
+		}
+
+		raw, err := c.getter.GetStringMap("balance_cache")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get balance_cache config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out balance cache"))
+		}
+
+		if cfg.RefreshInterval <= 0 {
+			panic(errors.New("balance_cache refresh_interval must be positive"))
+		}
+		if cfg.Concurrency <= 0 {
+			panic(errors.New("balance_cache concurrency must be positive"))
+		}
+		return cfg
+	}).(BalanceCacheConfig)
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"faucet-svc/internal/types"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type Batcher interface {
+	Batch() BatchConfig
+}
+
+// BatchConfig limits batch sends, which skip per request overhead and are meant for test harnesses
+type BatchConfig struct {
+	// MaxItems is the maximum number of sends in a batch
+	MaxItems int `fig:"max_items"`
+	// Roles are user roles allowed to send batches
+	Roles []string `fig:"roles"`
+}
+
+func (c BatchConfig) Allows(role string) bool {
+	for _, allowed := range c.Roles {
+		if allowed == role {
+			return true
+		}
+	}
+	return false
+}
+
+type batcher struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewBatcher(getter kv.Getter) Batcher {
+	return &batcher{getter: getter}
+}
+
+func (c *batcher) Batch() BatchConfig {
+	return c.once.Do(func() interface{} {
+		cfg := BatchConfig{
+			MaxItems: 50,
+			Roles:    []string{types.RoleTrusted, types.RoleAdmin},
+		}
+
+		raw, err := c.getter.GetStringMap("batch")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get batch config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out batch"))
+		}
+
+		if cfg.MaxItems <= 0 {
+			panic(errors.New("batch max_items must be positive"))
+		}
+		for _, role := range cfg.Roles {
+			// anonymous requests are never authorized for batches
+			if !types.IsKnownRole(role) || role == types.RoleAnonymous {
+				panic(errors.Errorf("unknown batch role %s", role))
+			}
+		}
+		return cfg
+	}).(BatchConfig)
+}
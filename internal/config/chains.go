@@ -5,16 +5,25 @@ import (
 	"faucet-svc/internal/types"
 	chains2 "faucet-svc/internal/types/chains"
 	client2 "github.com/eteu-technologies/near-api-go/pkg/client"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/portto/solana-go-sdk/client"
+	common2 "github.com/portto/solana-go-sdk/common"
 	types3 "github.com/portto/solana-go-sdk/types"
 	"gitlab.com/distributed_lab/figure/v3"
 	"gitlab.com/distributed_lab/kit/comfig"
 	"gitlab.com/distributed_lab/kit/kv"
 	"gitlab.com/distributed_lab/logan/v3"
 	"gitlab.com/distributed_lab/logan/v3/errors"
+	"time"
 )
 
+// defaultSolanaRetryInterval lets dropped transaction be resent many times before its blockhash expires in about a minute
+const defaultSolanaRetryInterval = 2 * time.Second
+
+// defaultNearRegistrar creates top level .testnet accounts
+const defaultNearRegistrar = "testnet"
+
 type Chainer interface {
 	Chains(signers Signers) chains2.Chains
 }
@@ -29,17 +38,27 @@ func NewChainer(getter kv.Getter) Chainer {
 }
 
 type evmChain struct {
-	ID          string  `fig:"id,required"`
-	Name        string  `fig:"name,required"`
-	RPC         string  `fig:"rpc,required"`
-	NativeToken string  `fig:"native_token,required"`
-	Decimals    float64 `fig:"decimals,required"`
+	ID          string `fig:"id,required"`
+	Name        string `fig:"name,required"`
+	RPC         string `fig:"rpc,required"`
+	NativeToken string `fig:"native_token,required"`
+	Decimals    uint8  `fig:"decimals,required"`
+	// Disperse is address of disperse.app compatible contract used to pay batches in one transaction
+	Disperse string `fig:"disperse"`
 }
 
 type solanaChain struct {
-	ID       string  `fig:"id,required"`
-	RPC      string  `fig:"rpc,required"`
-	Decimals float64 `fig:"decimals,required"`
+	ID       string `fig:"id,required"`
+	RPC      string `fig:"rpc,required"`
+	Decimals uint8  `fig:"decimals,required"`
+	// PriorityFee is compute unit price in micro-lamports
+	PriorityFee uint64 `fig:"priority_fee"`
+	// RetryInterval is how often unconfirmed transactions are resent, 0 disables resending
+	RetryInterval *time.Duration `fig:"retry_interval"`
+	// NonceAccount is durable nonce account with the signer as authority
+	NonceAccount string `fig:"nonce_account"`
+	// Strategy is how payouts are funded: transfer, airdrop or airdrop_then_transfer
+	Strategy string `fig:"strategy"`
 }
 
 func (c *chainer) Evm(chains *chains2.Chains, signer types.EvmSigner) {
@@ -78,7 +97,16 @@ func (c *chainer) Evm(chains *chains2.Chains, signer types.EvmSigner) {
 			panic(errors.Errorf("%s has different rpc and conf chain id", conf.Name))
 		}
 
-		ch := chains2.NewEvmChain(cli, signer, conf.ID, conf.Name, conf.NativeToken, conf.RPC, conf.Decimals)
+		var disperse *common.Address
+		if conf.Disperse != "" {
+			if !common.IsHexAddress(conf.Disperse) {
+				panic(errors.Errorf("invalid disperse contract address %s", conf.Disperse))
+			}
+			addr := common.HexToAddress(conf.Disperse)
+			disperse = &addr
+		}
+
+		ch := chains2.NewEvmChain(cli, signer, conf.ID, conf.Name, conf.NativeToken, conf.RPC, conf.Decimals, disperse)
 		chains.Set(ch.ID(), ch.Kind(), ch)
 	}
 	return
@@ -108,7 +136,34 @@ func (c *chainer) Solana(chains *chains2.Chains, signer types3.Account) {
 			panic(errors.Errorf("failed to get solana chain version, chain %s", conf.ID))
 		}
 
-		ch := chains2.NewSolanaChain(cli, signer, conf.ID, "SOL", conf.RPC, conf.Decimals)
+		opts := chains2.SolanaOptions{
+			Strategy:      chains2.SolanaStrategyTransfer,
+			PriorityFee:   conf.PriorityFee,
+			RetryInterval: defaultSolanaRetryInterval,
+		}
+		if conf.RetryInterval != nil {
+			opts.RetryInterval = *conf.RetryInterval
+		}
+		switch conf.Strategy {
+		case "":
+		case chains2.SolanaStrategyTransfer, chains2.SolanaStrategyAirdrop, chains2.SolanaStrategyAirdropThenTransfer:
+			opts.Strategy = conf.Strategy
+		default:
+			panic(errors.Errorf("unknown solana strategy %s, chain %s", conf.Strategy, conf.ID))
+		}
+		if conf.NonceAccount != "" {
+			nonceAccount, err := cli.GetNonceAccount(context.TODO(), conf.NonceAccount)
+			if err != nil {
+				panic(errors.Wrap(err, "failed to get solana nonce account", logan.F{"chain_id": conf.ID}))
+			}
+			if nonceAccount.AuthorizedPubkey != signer.PublicKey {
+				panic(errors.Errorf("solana nonce account %s is not authorized to the signer, chain %s", conf.NonceAccount, conf.ID))
+			}
+			accountKey := common2.PublicKeyFromString(conf.NonceAccount)
+			opts.NonceAccount = &accountKey
+		}
+
+		ch := chains2.NewSolanaChain(cli, signer, conf.ID, "SOL", conf.RPC, conf.Decimals, opts)
 		chains.Set(ch.ID(), ch.Kind(), ch)
 	}
 	return
@@ -116,9 +171,11 @@ func (c *chainer) Solana(chains *chains2.Chains, signer types3.Account) {
 
 func (c *chainer) Near(chains *chains2.Chains, signer types.NearSigner) {
 	var cfg struct {
-		ID       string  `fig:"id,required"`
-		RPC      string  `fig:"rpc,required"`
-		Decimals float64 `fig:"decimals,required"`
+		ID              string `fig:"id,required"`
+		RPC             string `fig:"rpc,required"`
+		Decimals        uint8  `fig:"decimals,required"`
+		AccountCreation string `fig:"account_creation"`
+		Registrar       string `fig:"registrar"`
 	}
 
 	err := figure.
@@ -135,7 +192,20 @@ func (c *chainer) Near(chains *chains2.Chains, signer types.NearSigner) {
 	if err != nil {
 		panic(errors.Wrap(err, "failed to dial near rpc"))
 	}
-	ch := chains2.NewNearChain(&cli, signer, cfg.ID, cfg.RPC, "NEAR", cfg.Decimals)
+
+	opts := chains2.NearOptions{AccountCreation: cfg.AccountCreation}
+	switch cfg.AccountCreation {
+	case "", chains2.NearAccountCreationSubAccount:
+	case chains2.NearAccountCreationRegistrar:
+		opts.Registrar = defaultNearRegistrar
+		if cfg.Registrar != "" {
+			opts.Registrar = cfg.Registrar
+		}
+	default:
+		panic(errors.Errorf("unknown near account creation %s", cfg.AccountCreation))
+	}
+
+	ch := chains2.NewNearChain(&cli, signer, cfg.ID, cfg.RPC, "NEAR", cfg.Decimals, opts)
 	chains.Set(ch.ID(), ch.Kind(), ch)
 	return
 }
@@ -170,7 +240,7 @@ func (v *duplicationEvmChainsValidator) validate(conf evmChain) error {
 	}
 
 	if _, ok := v.idsMap[conf.ID]; ok {
-		return errors.Errorf("chain_id %d is duplicated", conf.ID)
+		return errors.Errorf("chain_id %s is duplicated", conf.ID)
 	}
 
 	if _, ok := v.namesMap[conf.Name]; ok {
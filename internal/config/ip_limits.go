@@ -0,0 +1,131 @@
+package config
+
+import (
+	"net"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type IPLimiter interface {
+	IPLimits() IPLimits
+}
+
+// RateLimit allows Limit events within Window, zero Limit means unlimited
+type RateLimit struct {
+	Limit  uint64        `fig:"limit"`
+	Window time.Duration `fig:"window"`
+}
+
+// IPRateLimits limits events of a single ip and of the whole subnet it belongs to
+type IPRateLimits struct {
+	IP     RateLimit `fig:"ip"`
+	Subnet RateLimit `fig:"subnet"`
+}
+
+type IPLimits struct {
+	// TrustedProxies are networks of proxies X-Forwarded-For and X-Real-IP headers are accepted from
+	TrustedProxies []*net.IPNet
+	// IPv4SubnetBits and IPv6SubnetBits are prefix lengths ips are grouped into subnets by
+	IPv4SubnetBits int
+	IPv6SubnetBits int
+	Requests       IPRateLimits
+	Payouts        IPRateLimits
+}
+
+func (l IPLimits) IsTrusted(ip net.IP) bool {
+	for _, network := range l.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// Network returns ip itself as a single address network
+func (l IPLimits) Network(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		return &net.IPNet{IP: v4, Mask: net.CIDRMask(32, 32)}
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}
+}
+
+// Subnet returns network of configured size the ip belongs to
+func (l IPLimits) Subnet(ip net.IP) *net.IPNet {
+	if v4 := ip.To4(); v4 != nil {
+		mask := net.CIDRMask(l.IPv4SubnetBits, 32)
+		return &net.IPNet{IP: v4.Mask(mask), Mask: mask}
+	}
+	mask := net.CIDRMask(l.IPv6SubnetBits, 128)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}
+}
+
+type ipLimiter struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewIPLimiter(getter kv.Getter) IPLimiter {
+	return &ipLimiter{getter: getter}
+}
+
+func (c *ipLimiter) IPLimits() IPLimits {
+	return c.once.Do(func() interface{} {
+		cfg := struct {
+			TrustedProxies []string     `fig:"trusted_proxies"`
+			IPv4SubnetBits int          `fig:"ipv4_subnet_bits"`
+			IPv6SubnetBits int          `fig:"ipv6_subnet_bits"`
+			Requests       IPRateLimits `fig:"requests"`
+			Payouts        IPRateLimits `fig:"payouts"`
+		}{
+			IPv4SubnetBits: 24,
+			IPv6SubnetBits: 64,
+		}
+
+		raw, err := c.getter.GetStringMap("ip_limits")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get ip_limits config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out ip_limits"))
+		}
+
+		if cfg.IPv4SubnetBits < 0 || cfg.IPv4SubnetBits > 32 || cfg.IPv6SubnetBits < 0 || cfg.IPv6SubnetBits > 128 {
+			panic(errors.New("ip_limits subnet bits must fit ip length"))
+		}
+		for name, limit := range map[string]RateLimit{
+			"requests.ip":     cfg.Requests.IP,
+			"requests.subnet": cfg.Requests.Subnet,
+			"payouts.ip":      cfg.Payouts.IP,
+			"payouts.subnet":  cfg.Payouts.Subnet,
+		} {
+			if limit.Limit != 0 && limit.Window <= 0 {
+				panic(errors.Errorf("ip_limits %s window must be positive", name))
+			}
+		}
+
+		limits := IPLimits{
+			IPv4SubnetBits: cfg.IPv4SubnetBits,
+			IPv6SubnetBits: cfg.IPv6SubnetBits,
+			Requests:       cfg.Requests,
+			Payouts:        cfg.Payouts,
+		}
+		for _, cidr := range cfg.TrustedProxies {
+			_, network, err := net.ParseCIDR(cidr)
+			if err != nil {
+				panic(errors.Wrap(err, "invalid trusted proxy network", logan.F{"network": cidr}))
+			}
+			limits.TrustedProxies = append(limits.TrustedProxies, network)
+		}
+		return limits
+	}).(IPLimits)
+}
@@ -18,6 +18,15 @@ type Config interface {
 	Signerer
 	Tokens
 	DoormanConfiger
+	Auther
+	BalanceCacher
+	Quotaer
+	Adminer
+	Anonymouser
+	AddressPolicier
+	IPLimiter
+	Ownershiper
+	Batcher
 }
 
 type config struct {
@@ -31,9 +40,19 @@ type config struct {
 	Signerer
 	Tokens
 	DoormanConfiger
+	Auther
+	BalanceCacher
+	Quotaer
+	Adminer
+	Anonymouser
+	AddressPolicier
+	IPLimiter
+	Ownershiper
+	Batcher
 }
 
 func New(getter kv.Getter) Config {
+	doormanConfiger := NewDoormanConfiger(getter)
 	return &config{
 		getter:          getter,
 		Databaser:       pgdb.NewDatabaser(getter),
@@ -43,6 +62,15 @@ func New(getter kv.Getter) Config {
 		Chainer:         NewChainer(getter),
 		Signerer:        NewSignerer(getter),
 		Tokens:          NewTokens(getter),
-		DoormanConfiger: NewDoormanConfiger(getter),
+		DoormanConfiger: doormanConfiger,
+		Auther:          NewAuther(getter, doormanConfiger),
+		BalanceCacher:   NewBalanceCacher(getter),
+		Quotaer:         NewQuotaer(getter),
+		Adminer:         NewAdminer(getter),
+		Anonymouser:     NewAnonymouser(getter),
+		AddressPolicier: NewAddressPolicier(getter),
+		IPLimiter:       NewIPLimiter(getter),
+		Ownershiper:     NewOwnershiper(getter),
+		Batcher:         NewBatcher(getter),
 	}
 }
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"faucet-svc/internal/ownership"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type Ownershiper interface {
+	// Ownership returns nil if receivers are not required to prove address ownership
+	Ownership() *ownership.Issuer
+}
+
+// minOwnershipSecretLen guards nonces from being signed with placeholder secrets
+const minOwnershipSecretLen = 32
+
+type ownershiper struct {
+	once   comfig.Once
+	getter kv.Getter
+}
+
+func NewOwnershiper(getter kv.Getter) Ownershiper {
+	return &ownershiper{getter: getter}
+}
+
+func (c *ownershiper) Ownership() *ownership.Issuer {
+	return c.once.Do(func() interface{} {
+		cfg := struct {
+			Enabled bool   `fig:"enabled"`
+			Secret  string `fig:"secret"`
+			// TTL is how long issued nonce can be signed and used
+			TTL time.Duration `fig:"ttl"`
+		}{
+			TTL: 10 * time.Minute,
+		}
+
+		raw, err := c.getter.GetStringMap("ownership")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get ownership config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out ownership"))
+		}
+
+		if !cfg.Enabled {
+			return (*ownership.Issuer)(nil)
+		}
+		if len(cfg.Secret) < minOwnershipSecretLen {
+			panic(errors.Errorf("ownership secret must be at least %d characters long", minOwnershipSecretLen))
+		}
+		if cfg.TTL <= 0 {
+			panic(errors.New("ownership ttl must be positive"))
+		}
+		return ownership.NewIssuer(cfg.Secret, cfg.TTL)
+	}).(*ownership.Issuer)
+}
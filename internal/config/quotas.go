@@ -0,0 +1,136 @@
+package config
+
+import (
+	"faucet-svc/internal/types"
+	"math/big"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/figure/v3"
+	"gitlab.com/distributed_lab/kit/comfig"
+	"gitlab.com/distributed_lab/kit/kv"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type Quotaer interface {
+	Quotas() types.Quotas
+	RecipientBalances() types.RecipientBalances
+}
+
+type quotaer struct {
+	once              comfig.Once
+	recipientBalances comfig.Once
+	getter            kv.Getter
+}
+
+func NewQuotaer(getter kv.Getter) Quotaer {
+	return &quotaer{getter: getter}
+}
+
+type quota struct {
+	Role         string        `fig:"role"`
+	ChainType    string        `fig:"chain_type,required"`
+	ChainID      string        `fig:"chain_id"`
+	TokenAddress string        `fig:"token_address"`
+	MaxAmount    *big.Int      `fig:"max_amount"`
+	Cooldown     time.Duration `fig:"cooldown"`
+}
+
+func (q *quotaer) Quotas() types.Quotas {
+	return q.once.Do(func() interface{} {
+		var cfg struct {
+			Quotas []quota `fig:"quotas"`
+		}
+
+		raw, err := q.getter.GetStringMap("limits")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get limits config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out limits"))
+		}
+
+		return newQuotas(cfg.Quotas)
+	}).(types.Quotas)
+}
+
+type recipientBalance struct {
+	ChainType    string   `fig:"chain_type,required"`
+	ChainID      string   `fig:"chain_id"`
+	TokenAddress string   `fig:"token_address"`
+	MaxBalance   *big.Int `fig:"max_balance"`
+	TopUpTo      *big.Int `fig:"top_up_to"`
+}
+
+func (q *quotaer) RecipientBalances() types.RecipientBalances {
+	return q.recipientBalances.Do(func() interface{} {
+		var cfg struct {
+			RecipientBalances []recipientBalance `fig:"recipient_balances"`
+		}
+
+		raw, err := q.getter.GetStringMap("limits")
+		if err != nil {
+			panic(errors.Wrap(err, "failed to get limits config"))
+		}
+
+		err = figure.
+			Out(&cfg).
+			From(raw).
+			Please()
+		if err != nil {
+			panic(errors.Wrap(err, "failed to figure out limits"))
+		}
+
+		policies := make(types.RecipientBalances, 0, len(cfg.RecipientBalances))
+		for _, conf := range cfg.RecipientBalances {
+			if conf.TokenAddress != "" && conf.ChainID == "" {
+				panic(errors.Errorf("token recipient balance %s must have chain_id", conf.TokenAddress))
+			}
+			if conf.MaxBalance != nil && conf.MaxBalance.Sign() <= 0 {
+				panic(errors.Errorf("max_balance of %s recipient balance must be positive", conf.ChainType))
+			}
+			if conf.TopUpTo != nil && conf.TopUpTo.Sign() <= 0 {
+				panic(errors.Errorf("top_up_to of %s recipient balance must be positive", conf.ChainType))
+			}
+
+			policies = append(policies, types.RecipientBalance{
+				ChainType:    conf.ChainType,
+				ChainID:      conf.ChainID,
+				TokenAddress: strings.ToLower(conf.TokenAddress),
+				MaxBalance:   conf.MaxBalance,
+				TopUpTo:      conf.TopUpTo,
+			})
+		}
+		return policies
+	}).(types.RecipientBalances)
+}
+
+func newQuotas(confs []quota) types.Quotas {
+	quotas := make(types.Quotas, 0, len(confs))
+	for _, conf := range confs {
+		if conf.TokenAddress != "" && conf.ChainID == "" {
+			panic(errors.Errorf("token quota %s must have chain_id", conf.TokenAddress))
+		}
+		if conf.Role != "" && !types.IsKnownRole(conf.Role) {
+			panic(errors.Errorf("unknown role %s of %s quota", conf.Role, conf.ChainType))
+		}
+		if conf.MaxAmount != nil && conf.MaxAmount.Sign() <= 0 {
+			panic(errors.Errorf("max_amount of %s quota must be positive", conf.ChainType))
+		}
+
+		quotas = append(quotas, types.Quota{
+			Role:         conf.Role,
+			ChainType:    conf.ChainType,
+			ChainID:      conf.ChainID,
+			TokenAddress: strings.ToLower(conf.TokenAddress),
+			MaxAmount:    conf.MaxAmount,
+			Cooldown:     conf.Cooldown,
+		})
+	}
+	return quotas
+}
@@ -71,8 +71,9 @@ func (s *signerer) Solana() types2.Account {
 
 func (s *signerer) Near() types.NearSigner {
 	var cfg struct {
-		ID      types3.AccountID `fig:"signer_id,required"`
-		PrivKey string           `fig:"signer,required"`
+		ID         types3.AccountID `fig:"signer_id,required"`
+		PrivKey    string           `fig:"signer,required"`
+		AccessKeys []string         `fig:"access_keys"`
 	}
 
 	err := figure.
@@ -84,7 +85,7 @@ func (s *signerer) Near() types.NearSigner {
 		panic(errors.Wrap(err, "failed to figure out near signer"))
 	}
 
-	signer, err := types.NewNearSigner(cfg.ID, cfg.PrivKey)
+	signer, err := types.NewNearSigner(cfg.ID, cfg.PrivKey, cfg.AccessKeys...)
 	if err != nil {
 		panic(errors.Wrap(err, "failed to get near signer"))
 	}
@@ -29,7 +29,7 @@ type token struct {
 	Address  string   `fig:"address,required"`
 	Kind     string   `fig:"type,required"`
 	Chains   []string `fig:"chains,required"`
-	Decimals float64  `fig:"decimals,required"`
+	Decimals uint8    `fig:"decimals,required"`
 }
 
 func (c *tokens) EvmTokens() types.EvmTokens {
@@ -0,0 +1,13 @@
+pragma solidity ^0.8.0;
+
+import "./erc20.sol";
+
+/**
+ * @dev Interface of disperse.app contract paying many transfers in one transaction.
+ */
+interface IDisperse {
+
+    function disperseEther(address[] calldata recipients, uint256[] calldata values) external payable;
+    function disperseToken(IERC20 token, address[] calldata recipients, uint256[] calldata values) external;
+
+}
@@ -0,0 +1,17 @@
+package data
+
+import (
+	"errors"
+	"faucet-svc/internal/types/pg"
+)
+
+// ErrAddressBound - address is already bound to some user
+var ErrAddressBound = errors.New("address is already bound")
+
+type AddressesQ interface {
+	New() AddressesQ
+	Insert(address *pg.Address) error
+	Get() (*pg.Address, error)
+	FilterByChainType(chainType string) AddressesQ
+	FilterByAddress(address string) AddressesQ
+}
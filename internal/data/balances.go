@@ -1,16 +1,41 @@
 package data
 
 import (
+	"errors"
 	"faucet-svc/internal/types/pg"
+	"math/big"
+	"time"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+var (
+	// ErrQuotaExceeded - claimed amount of the user would exceed max amount of the quota
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrClaimCooldown - user has claimed the currency within cooldown of the quota
+	ErrClaimCooldown = errors.New("claim cooldown has not passed")
 )
 
+// Claim is counted to user balance, it is checked against the quota under row lock
+type Claim struct {
+	// At is time of the claim, updates made with the same time count as a single claim for cooldown
+	At time.Time
+	// MaxAmount is total amount user can claim, nil means unlimited
+	MaxAmount *big.Int
+	Cooldown  time.Duration
+}
+
 type BalancesQ interface {
 	New() BalancesQ
-	Create(balance *pg.Balance) error
 	Get() (*pg.Balance, error)
-	Update(balance *pg.Balance) error
+	Select() ([]pg.Balance, error)
+	// Update increments claimed amount, ErrQuotaExceeded is returned if it would exceed max amount of the claim
+	// and ErrClaimCooldown if previous claim was made within the cooldown
+	Update(balance *pg.Balance, claim Claim) error
+	Subtract(balance *pg.Balance) error
 	FilterByUserID(userId string) BalancesQ
 	FilterByChainID(chainId string) BalancesQ
 	FilterByChainType(chainType string) BalancesQ
 	FilterByTokenAddress(tokenAddress string) BalancesQ
+	Page(params pgdb.OffsetPageParams) BalancesQ
 }
@@ -0,0 +1,19 @@
+package data
+
+import (
+	"faucet-svc/internal/types/pg"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+type BlocksQ interface {
+	New() BlocksQ
+	// Upsert creates block or updates reason of existing one
+	Upsert(block *pg.Block) error
+	Delete() error
+	Get() (*pg.Block, error)
+	Select() ([]pg.Block, error)
+	FilterByKind(kind pg.BlockKind) BlocksQ
+	FilterByValue(values ...string) BlocksQ
+	Page(params pgdb.OffsetPageParams) BlocksQ
+}
@@ -0,0 +1,16 @@
+package data
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrChallengeUsed - proof of work challenge was already used for a send
+var ErrChallengeUsed = errors.New("challenge is already used")
+
+// ChallengesQ keeps used proof of work challenges until they expire, so solutions can't be replayed
+type ChallengesQ interface {
+	New() ChallengesQ
+	Insert(id string, expiresAt time.Time) error
+	DeleteExpired(now time.Time) error
+}
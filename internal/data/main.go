@@ -0,0 +1,12 @@
+package data
+
+// MasterQ gives access to all queriers sharing one connection, so they can be used in a single db transaction
+type MasterQ interface {
+	New() MasterQ
+	Balances() BalancesQ
+	Payouts() PayoutsQ
+	Blocks() BlocksQ
+	Challenges() ChallengesQ
+	Addresses() AddressesQ
+	Transaction(fn func(q MasterQ) error) error
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"errors"
+	"faucet-svc/internal/types/pg"
+	"time"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+// ErrIdempotencyKeyConflict - user already has a payout with the same idempotency key
+var ErrIdempotencyKeyConflict = errors.New("payout idempotency key conflict")
+
+// ErrAlreadyRefunded - failed payout already has a refund payout
+var ErrAlreadyRefunded = errors.New("payout is already refunded")
+
+type PayoutsQ interface {
+	New() PayoutsQ
+	Insert(payout *pg.Payout) error
+	Get() (*pg.Payout, error)
+	Select() ([]pg.Payout, error)
+	// Totals sums amount of filtered payouts
+	Totals() (pg.PayoutTotals, error)
+	UpdateStatus(id uint64, status pg.PayoutStatus) error
+	UpdateTxHash(id uint64, txHash string) error
+	FilterByID(id uint64) PayoutsQ
+	FilterByUserID(userId string) PayoutsQ
+	FilterByIdempotencyKey(key string) PayoutsQ
+	FilterByStatus(statuses ...pg.PayoutStatus) PayoutsQ
+	FilterByCreatedBefore(before time.Time) PayoutsQ
+	FilterByCreatedAfter(after time.Time) PayoutsQ
+	FilterByReceiver(receiver string) PayoutsQ
+	FilterByChainType(chainType string) PayoutsQ
+	FilterByChainID(chainId string) PayoutsQ
+	FilterByTokenAddress(tokenAddress string) PayoutsQ
+	// FilterByNetwork matches payouts requested from ips of the network, network is an ip or cidr
+	FilterByNetwork(network string) PayoutsQ
+	Page(params pgdb.OffsetPageParams) PayoutsQ
+}
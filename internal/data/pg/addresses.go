@@ -0,0 +1,73 @@
+package pg
+
+import (
+	"database/sql"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/types/pg"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	addressesTableName  = "addresses"
+	addressesPrimaryKey = "addresses_pkey"
+)
+
+func NewAddressesQ(db *pgdb.DB) data.AddressesQ {
+	return newAddressesQ(db.Clone())
+}
+
+func newAddressesQ(db *pgdb.DB) data.AddressesQ {
+	return &AddressesQ{
+		db:  db,
+		sql: sq.Select("a.*").From(fmt.Sprintf("%s as a", addressesTableName)),
+	}
+}
+
+type AddressesQ struct {
+	db  *pgdb.DB
+	sql sq.SelectBuilder
+}
+
+func (q *AddressesQ) New() data.AddressesQ {
+	return NewAddressesQ(q.db)
+}
+
+func (q *AddressesQ) Insert(address *pg.Address) error {
+	stmt := sq.Insert(addressesTableName).SetMap(map[string]interface{}{
+		"chain_type": address.ChainType,
+		"address":    address.Address,
+		"user_id":    address.UserId,
+	}).Suffix("RETURNING verified_at")
+
+	err := q.db.Get(&address.VerifiedAt, stmt)
+	if pgdb.IsConstraintErr(err, addressesPrimaryKey) {
+		return data.ErrAddressBound
+	}
+	return err
+}
+
+func (q *AddressesQ) Get() (*pg.Address, error) {
+	var result pg.Address
+	err := q.db.Get(&result, q.sql)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (q *AddressesQ) FilterByChainType(chainType string) data.AddressesQ {
+	q.sql = q.sql.Where(sq.Eq{"a.chain_type": chainType})
+	return q
+}
+
+func (q *AddressesQ) FilterByAddress(address string) data.AddressesQ {
+	q.sql = q.sql.Where(sq.Eq{"a.address": address})
+	return q
+}
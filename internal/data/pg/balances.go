@@ -6,23 +6,20 @@ import (
 	"faucet-svc/internal/types/pg"
 	"fmt"
 	sq "github.com/Masterminds/squirrel"
-	"github.com/lib/pq"
 	"github.com/pkg/errors"
 	"gitlab.com/distributed_lab/kit/pgdb"
+	"time"
 )
 
-const (
-	balancesTableName  = "balances"
-	balancesConstraint = "uniq_idx_balances"
-)
-
-var (
-	ErrUsersConflict = errors.New("balances primary key conflict")
-)
+const balancesTableName = "balances"
 
 func NewBalancesQ(db *pgdb.DB) data.BalancesQ {
+	return newBalancesQ(db.Clone())
+}
+
+func newBalancesQ(db *pgdb.DB) data.BalancesQ {
 	return &BalancesQ{
-		db:  db.Clone(),
+		db:  db,
 		sql: sq.Select("b.*").From(fmt.Sprintf("%s as b", balancesTableName)),
 	}
 }
@@ -36,27 +33,6 @@ func (q *BalancesQ) New() data.BalancesQ {
 	return NewBalancesQ(q.db)
 }
 
-func (q *BalancesQ) Create(balance *pg.Balance) error {
-	stmt := sq.Insert(balancesTableName).SetMap(map[string]interface{}{
-		"user_id":       balance.UserId,
-		"chain_id":      balance.ChainId,
-		"chain_type":    balance.ChainType,
-		"token_address": balance.TokenAddress,
-		"amount":        balance.Amount,
-	})
-
-	err := q.db.Exec(stmt)
-	if err != nil {
-		pqerr, ok := errors.Cause(err).(*pq.Error)
-		if ok {
-			if pqerr.Constraint == balancesConstraint {
-				return ErrUsersConflict
-			}
-		}
-	}
-	return err
-}
-
 func (q *BalancesQ) Get() (*pg.Balance, error) {
 	var result pg.Balance
 	err := q.db.Get(&result, q.sql)
@@ -70,6 +46,17 @@ func (q *BalancesQ) Get() (*pg.Balance, error) {
 	return &result, nil
 }
 
+func (q *BalancesQ) Select() ([]pg.Balance, error) {
+	var result []pg.Balance
+	err := q.db.Select(&result, q.sql)
+	return result, err
+}
+
+func (q *BalancesQ) Page(params pgdb.OffsetPageParams) data.BalancesQ {
+	q.sql = params.ApplyTo(q.sql, "b.id")
+	return q
+}
+
 func (q *BalancesQ) FilterByUserID(userId string) data.BalancesQ {
 	q.sql = q.sql.Where(sq.Eq{"b.user_id": userId})
 	return q
@@ -90,28 +77,66 @@ func (q *BalancesQ) FilterByTokenAddress(tokenAddress string) data.BalancesQ {
 	return q
 }
 
-func (q *BalancesQ) Update(balance *pg.Balance) error {
-	exist, err := q.FilterByUserID(balance.UserId).
+// Update atomically increments claimed amount of the user, creating the row on the first claim.
+// Conflicting row is locked by the upsert, so concurrent claims are checked against max amount and cooldown one by one.
+func (q *BalancesQ) Update(balance *pg.Balance, claim data.Claim) error {
+	if claim.MaxAmount != nil && balance.Amount.Cmp(claim.MaxAmount) > 0 {
+		return data.ErrQuotaExceeded
+	}
+
+	// column keeps microseconds, so claim time is stored as is and updates of the same claim are matched by it
+	claimedAt := claim.At.UTC().Truncate(time.Microsecond)
+	suffix := fmt.Sprintf(
+		"ON CONFLICT (user_id, chain_type, chain_id, token_address) DO UPDATE SET "+
+			"amount = %[1]s.amount + EXCLUDED.amount, last_claim_at = EXCLUDED.last_claim_at "+
+			"WHERE (%[1]s.last_claim_at = EXCLUDED.last_claim_at OR "+
+			"%[1]s.last_claim_at <= EXCLUDED.last_claim_at - ?::bigint * interval '1 microsecond')",
+		balancesTableName,
+	)
+	args := []interface{}{claim.Cooldown.Microseconds()}
+	if claim.MaxAmount != nil {
+		suffix += fmt.Sprintf(" AND %s.amount + EXCLUDED.amount <= ?::numeric", balancesTableName)
+		args = append(args, claim.MaxAmount.String())
+	}
+
+	stmt := sq.Insert(balancesTableName).SetMap(map[string]interface{}{
+		"user_id":       balance.UserId,
+		"chain_id":      balance.ChainId,
+		"chain_type":    balance.ChainType,
+		"token_address": balance.TokenAddress,
+		"amount":        balance.Amount,
+		"last_claim_at": claimedAt,
+	}).Suffix(suffix+" RETURNING id", args...)
+
+	err := q.db.Get(&balance.ID, stmt)
+	if err != sql.ErrNoRows {
+		return err
+	}
+
+	// conflicting row is not updated, it stays locked, so it tells which limit the claim doesn't fit into
+	claimed, err := newBalancesQ(q.db).
+		FilterByUserID(balance.UserId).
 		FilterByChainID(balance.ChainId).
 		FilterByChainType(balance.ChainType).
 		FilterByTokenAddress(balance.TokenAddress).
 		Get()
 	if err != nil {
-		return err
+		return errors.Wrap(err, "failed to get claimed balance")
 	}
-
-	if exist == nil {
-		err = q.Create(balance)
-		return err
+	if claimed != nil && !claimed.LastClaimAt.Equal(claimedAt) && claimed.LastClaimAt.After(claimedAt.Add(-claim.Cooldown)) {
+		return data.ErrClaimCooldown
 	}
+	return data.ErrQuotaExceeded
+}
 
+// Subtract returns amount of failed payout back to the user quota
+func (q *BalancesQ) Subtract(balance *pg.Balance) error {
 	stmt := sq.Update(balancesTableName).
-		Set("amount", sq.Expr("amount+?", balance.Amount)).
+		Set("amount", sq.Expr("greatest(amount-?::numeric, 0)", balance.Amount)).
 		Where(sq.Eq{"user_id": balance.UserId}).
 		Where(sq.Eq{"chain_id": balance.ChainId}).
 		Where(sq.Eq{"chain_type": balance.ChainType}).
 		Where(sq.Eq{"token_address": balance.TokenAddress})
 
-	err = q.db.Exec(stmt)
-	return err
+	return q.db.Exec(stmt)
 }
@@ -0,0 +1,89 @@
+package pg
+
+import (
+	"database/sql"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/types/pg"
+	"fmt"
+
+	sq "github.com/Masterminds/squirrel"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const blocksTableName = "blocks"
+
+func NewBlocksQ(db *pgdb.DB) data.BlocksQ {
+	return newBlocksQ(db.Clone())
+}
+
+func newBlocksQ(db *pgdb.DB) data.BlocksQ {
+	return &BlocksQ{
+		db:  db,
+		sql: sq.Select("bl.*").From(fmt.Sprintf("%s as bl", blocksTableName)),
+		del: sq.Delete(blocksTableName),
+	}
+}
+
+type BlocksQ struct {
+	db  *pgdb.DB
+	sql sq.SelectBuilder
+	del sq.DeleteBuilder
+}
+
+func (q *BlocksQ) New() data.BlocksQ {
+	return NewBlocksQ(q.db)
+}
+
+func (q *BlocksQ) Upsert(block *pg.Block) error {
+	stmt := sq.Insert(blocksTableName).SetMap(map[string]interface{}{
+		"kind":       block.Kind,
+		"value":      block.Value,
+		"reason":     block.Reason,
+		"created_by": block.CreatedBy,
+	}).Suffix(
+		"ON CONFLICT (kind, value) DO UPDATE SET reason = EXCLUDED.reason, created_by = EXCLUDED.created_by " +
+			"RETURNING created_at",
+	)
+
+	return q.db.Get(&block.CreatedAt, stmt)
+}
+
+func (q *BlocksQ) Delete() error {
+	return q.db.Exec(q.del)
+}
+
+func (q *BlocksQ) Get() (*pg.Block, error) {
+	var result pg.Block
+	err := q.db.Get(&result, q.sql)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (q *BlocksQ) Select() ([]pg.Block, error) {
+	var result []pg.Block
+	err := q.db.Select(&result, q.sql)
+	return result, err
+}
+
+func (q *BlocksQ) FilterByKind(kind pg.BlockKind) data.BlocksQ {
+	q.sql = q.sql.Where(sq.Eq{"bl.kind": kind})
+	q.del = q.del.Where(sq.Eq{"kind": kind})
+	return q
+}
+
+func (q *BlocksQ) FilterByValue(values ...string) data.BlocksQ {
+	q.sql = q.sql.Where(sq.Eq{"bl.value": values})
+	q.del = q.del.Where(sq.Eq{"value": values})
+	return q
+}
+
+func (q *BlocksQ) Page(params pgdb.OffsetPageParams) data.BlocksQ {
+	q.sql = params.ApplyTo(q.sql, "bl.created_at")
+	return q
+}
@@ -0,0 +1,47 @@
+package pg
+
+import (
+	"faucet-svc/internal/data"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	challengesTableName  = "pow_challenges"
+	challengesPrimaryKey = "pow_challenges_pkey"
+)
+
+func NewChallengesQ(db *pgdb.DB) data.ChallengesQ {
+	return newChallengesQ(db.Clone())
+}
+
+func newChallengesQ(db *pgdb.DB) data.ChallengesQ {
+	return &ChallengesQ{db: db}
+}
+
+type ChallengesQ struct {
+	db *pgdb.DB
+}
+
+func (q *ChallengesQ) New() data.ChallengesQ {
+	return NewChallengesQ(q.db)
+}
+
+func (q *ChallengesQ) Insert(id string, expiresAt time.Time) error {
+	stmt := sq.Insert(challengesTableName).SetMap(map[string]interface{}{
+		"id":         id,
+		"expires_at": expiresAt.UTC(),
+	})
+
+	err := q.db.Exec(stmt)
+	if pgdb.IsConstraintErr(err, challengesPrimaryKey) {
+		return data.ErrChallengeUsed
+	}
+	return err
+}
+
+func (q *ChallengesQ) DeleteExpired(now time.Time) error {
+	return q.db.Exec(sq.Delete(challengesTableName).Where(sq.Lt{"expires_at": now.UTC()}))
+}
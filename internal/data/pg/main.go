@@ -0,0 +1,52 @@
+package pg
+
+import (
+	"faucet-svc/internal/data"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+func NewMasterQ(db *pgdb.DB) data.MasterQ {
+	return &masterQ{
+		db: db.Clone(),
+	}
+}
+
+type masterQ struct {
+	db *pgdb.DB
+}
+
+func (q *masterQ) New() data.MasterQ {
+	return NewMasterQ(q.db)
+}
+
+// Balances - querier shares connection with master, so it is a part of the ongoing transaction if any
+func (q *masterQ) Balances() data.BalancesQ {
+	return newBalancesQ(q.db)
+}
+
+// Payouts - querier shares connection with master, so it is a part of the ongoing transaction if any
+func (q *masterQ) Payouts() data.PayoutsQ {
+	return newPayoutsQ(q.db)
+}
+
+// Blocks - querier shares connection with master, so it is a part of the ongoing transaction if any
+func (q *masterQ) Blocks() data.BlocksQ {
+	return newBlocksQ(q.db)
+}
+
+// Challenges - querier shares connection with master, so it is a part of the ongoing transaction if any
+func (q *masterQ) Challenges() data.ChallengesQ {
+	return newChallengesQ(q.db)
+}
+
+// Addresses - querier shares connection with master, so it is a part of the ongoing transaction if any
+func (q *masterQ) Addresses() data.AddressesQ {
+	return newAddressesQ(q.db)
+}
+
+func (q *masterQ) Transaction(fn func(q data.MasterQ) error) error {
+	return q.db.Transaction(func() error {
+		return fn(q)
+	})
+}
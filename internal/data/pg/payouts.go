@@ -0,0 +1,175 @@
+package pg
+
+import (
+	"database/sql"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/types/pg"
+	"fmt"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+const (
+	payoutsTableName             = "payouts"
+	payoutsIdempotencyConstraint = "payouts_idempotency_key_idx"
+	payoutsRefundConstraint      = "payouts_refund_of_idx"
+)
+
+func NewPayoutsQ(db *pgdb.DB) data.PayoutsQ {
+	return newPayoutsQ(db.Clone())
+}
+
+func newPayoutsQ(db *pgdb.DB) data.PayoutsQ {
+	return &PayoutsQ{
+		db:  db,
+		sql: sq.Select("p.*").From(fmt.Sprintf("%s as p", payoutsTableName)),
+	}
+}
+
+type PayoutsQ struct {
+	db  *pgdb.DB
+	sql sq.SelectBuilder
+}
+
+func (q *PayoutsQ) New() data.PayoutsQ {
+	return NewPayoutsQ(q.db)
+}
+
+func (q *PayoutsQ) Insert(payout *pg.Payout) error {
+	stmt := sq.Insert(payoutsTableName).SetMap(map[string]interface{}{
+		"user_id":         payout.UserId,
+		"chain_type":      payout.ChainType,
+		"chain_id":        payout.ChainId,
+		"token_address":   payout.TokenAddress,
+		"receiver":        payout.Receiver,
+		"amount":          payout.Amount,
+		"tx_hash":         payout.TxHash,
+		"status":          payout.Status,
+		"idempotency_key": payout.IdempotencyKey,
+		"request_hash":    payout.RequestHash,
+		"refund_of":       payout.RefundOf,
+		"ip":              payout.IP,
+	}).Suffix("RETURNING id, created_at, updated_at")
+
+	err := q.db.Get(payout, stmt)
+	if pgdb.IsConstraintErr(err, payoutsIdempotencyConstraint) {
+		return data.ErrIdempotencyKeyConflict
+	}
+	if pgdb.IsConstraintErr(err, payoutsRefundConstraint) {
+		return data.ErrAlreadyRefunded
+	}
+	return err
+}
+
+func (q *PayoutsQ) Get() (*pg.Payout, error) {
+	var result pg.Payout
+	err := q.db.Get(&result, q.sql)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+func (q *PayoutsQ) Select() ([]pg.Payout, error) {
+	var result []pg.Payout
+	err := q.db.Select(&result, q.sql)
+	return result, err
+}
+
+func (q *PayoutsQ) Totals() (pg.PayoutTotals, error) {
+	var result pg.PayoutTotals
+	stmt := sq.Select("count(*) as count", "count(distinct t.user_id) as users", "coalesce(sum(t.amount), 0) as amount", "max(t.created_at) as last_created_at").
+		FromSelect(q.sql, "t")
+	err := q.db.Get(&result, stmt)
+	return result, err
+}
+
+func (q *PayoutsQ) UpdateStatus(id uint64, status pg.PayoutStatus) error {
+	stmt := sq.Update(payoutsTableName).
+		Set("status", status).
+		Set("updated_at", sq.Expr("now() at time zone 'utc'")).
+		Where(sq.Eq{"id": id})
+
+	return q.db.Exec(stmt)
+}
+
+func (q *PayoutsQ) UpdateTxHash(id uint64, txHash string) error {
+	stmt := sq.Update(payoutsTableName).
+		Set("tx_hash", txHash).
+		Set("updated_at", sq.Expr("now() at time zone 'utc'")).
+		Where(sq.Eq{"id": id})
+
+	return q.db.Exec(stmt)
+}
+
+func (q *PayoutsQ) FilterByID(id uint64) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.id": id})
+	return q
+}
+
+func (q *PayoutsQ) FilterByUserID(userId string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.user_id": userId})
+	return q
+}
+
+func (q *PayoutsQ) FilterByIdempotencyKey(key string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.idempotency_key": key})
+	return q
+}
+
+func (q *PayoutsQ) FilterByStatus(statuses ...pg.PayoutStatus) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.status": statuses})
+	return q
+}
+
+func (q *PayoutsQ) FilterByCreatedBefore(before time.Time) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Lt{"p.created_at": before})
+	return q
+}
+
+func (q *PayoutsQ) FilterByCreatedAfter(after time.Time) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.GtOrEq{"p.created_at": after})
+	return q
+}
+
+// FilterByReceiver matches evm receivers case-insensitively, as they may come in checksum case,
+// receivers of other chains are case-sensitive and matched exactly
+func (q *PayoutsQ) FilterByReceiver(receiver string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Or{
+		sq.And{sq.Eq{"p.chain_type": "evm"}, sq.Expr("lower(p.receiver) = lower(?)", receiver)},
+		sq.And{sq.NotEq{"p.chain_type": "evm"}, sq.Eq{"p.receiver": receiver}},
+	})
+	return q
+}
+
+func (q *PayoutsQ) FilterByChainType(chainType string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.chain_type": chainType})
+	return q
+}
+
+func (q *PayoutsQ) FilterByChainID(chainId string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.chain_id": chainId})
+	return q
+}
+
+func (q *PayoutsQ) FilterByTokenAddress(tokenAddress string) data.PayoutsQ {
+	q.sql = q.sql.Where(sq.Eq{"p.token_address": tokenAddress})
+	return q
+}
+
+// FilterByNetwork matches payouts requested from ips of the network, network is an ip or cidr
+func (q *PayoutsQ) FilterByNetwork(network string) data.PayoutsQ {
+	q.sql = q.sql.Where("p.ip <<= ?::inet", network)
+	return q
+}
+
+func (q *PayoutsQ) Page(params pgdb.OffsetPageParams) data.PayoutsQ {
+	q.sql = params.ApplyTo(q.sql, "p.id")
+	return q
+}
@@ -0,0 +1,96 @@
+package ownership
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var (
+	// ErrInvalid is returned for nonces which were not issued by the service for the address or are malformed
+	ErrInvalid = errors.New("invalid nonce")
+	ErrExpired = errors.New("nonce expired")
+)
+
+// Nonce is a server issued value the address owner signs to prove ownership.
+// It is bound to chain type and address, so signature can't be used for another address.
+type Nonce struct {
+	Value     string
+	ExpiresAt time.Time
+	// Message is the exact text to be signed
+	Message string
+}
+
+type Issuer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewIssuer(secret string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// Issue creates nonce for the address, address is expected to be normalized
+func (i *Issuer) Issue(chainType, address string) (Nonce, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return Nonce{}, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	expiresAt := time.Now().UTC().Add(i.ttl).Truncate(time.Second)
+	payload := fmt.Sprintf("%s.%d", hex.EncodeToString(raw), expiresAt.Unix())
+	value := payload + "." + i.sign(chainType, address, payload)
+	return Nonce{
+		Value:     value,
+		ExpiresAt: expiresAt,
+		Message:   message(chainType, address, value),
+	}, nil
+}
+
+// Parse checks that nonce was issued for the address and is not expired
+func (i *Issuer) Parse(value, chainType, address string) (Nonce, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 {
+		return Nonce{}, ErrInvalid
+	}
+
+	payload := strings.Join(parts[:2], ".")
+	if !hmac.Equal([]byte(i.sign(chainType, address, payload)), []byte(parts[2])) {
+		return Nonce{}, ErrInvalid
+	}
+
+	expiresAt, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return Nonce{}, ErrInvalid
+	}
+
+	nonce := Nonce{
+		Value:     value,
+		ExpiresAt: time.Unix(expiresAt, 0).UTC(),
+		Message:   message(chainType, address, value),
+	}
+	if time.Now().After(nonce.ExpiresAt) {
+		return Nonce{}, ErrExpired
+	}
+	return nonce, nil
+}
+
+func (i *Issuer) sign(chainType, address, payload string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(strings.Join([]string{chainType, address, payload}, ":")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func message(chainType, address, nonce string) string {
+	return fmt.Sprintf("Sign to prove ownership of %s address %s for faucet.\n\nNonce: %s", chainType, address, nonce)
+}
@@ -0,0 +1,17 @@
+package pow
+
+// Difficulty grows by one bit, i.e. twice the work, for every step of payouts made recently
+type Difficulty struct {
+	Base uint8
+	Max  uint8
+	// Step is the number of recent payouts adding one bit of difficulty
+	Step uint64
+}
+
+func (d Difficulty) For(recentPayouts uint64) uint8 {
+	extra := recentPayouts / d.Step
+	if extra > uint64(d.Max-d.Base) {
+		return d.Max
+	}
+	return d.Base + uint8(extra)
+}
@@ -0,0 +1,115 @@
+package pow
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+var (
+	// ErrInvalid is returned for challenges which were not issued by the service or are malformed
+	ErrInvalid = errors.New("invalid challenge")
+	ErrExpired = errors.New("challenge expired")
+)
+
+// Challenge is hashcash-like puzzle: client has to find solution such that
+// sha256(challenge:receiver:amount:solution) starts with Difficulty zero bits
+type Challenge struct {
+	ID         string
+	Difficulty uint8
+	ExpiresAt  time.Time
+	// Token is signed challenge representation client sends back with solution
+	Token string
+}
+
+type Issuer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+func NewIssuer(secret string, ttl time.Duration) *Issuer {
+	return &Issuer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+func (i *Issuer) Issue(difficulty uint8) (Challenge, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return Challenge{}, errors.Wrap(err, "failed to generate challenge id")
+	}
+
+	challenge := Challenge{
+		ID:         hex.EncodeToString(raw),
+		Difficulty: difficulty,
+		ExpiresAt:  time.Now().UTC().Add(i.ttl).Truncate(time.Second),
+	}
+	payload := fmt.Sprintf("%s.%d.%d", challenge.ID, challenge.Difficulty, challenge.ExpiresAt.Unix())
+	challenge.Token = payload + "." + i.sign(payload)
+	return challenge, nil
+}
+
+// Parse checks signature and expiration of the challenge token
+func (i *Issuer) Parse(token string) (Challenge, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 4 {
+		return Challenge{}, ErrInvalid
+	}
+
+	payload := strings.Join(parts[:3], ".")
+	if !hmac.Equal([]byte(i.sign(payload)), []byte(parts[3])) {
+		return Challenge{}, ErrInvalid
+	}
+
+	difficulty, err := strconv.ParseUint(parts[1], 10, 8)
+	if err != nil {
+		return Challenge{}, ErrInvalid
+	}
+	expiresAt, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return Challenge{}, ErrInvalid
+	}
+
+	challenge := Challenge{
+		ID:         parts[0],
+		Difficulty: uint8(difficulty),
+		ExpiresAt:  time.Unix(expiresAt, 0).UTC(),
+		Token:      token,
+	}
+	if time.Now().After(challenge.ExpiresAt) {
+		return Challenge{}, ErrExpired
+	}
+	return challenge, nil
+}
+
+func (i *Issuer) sign(payload string) string {
+	mac := hmac.New(sha256.New, i.secret)
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks that solution solves the challenge for the given receiver and amount in base units
+func Verify(challenge Challenge, receiver, amount, solution string) bool {
+	sum := sha256.Sum256([]byte(strings.Join([]string{challenge.Token, receiver, amount, solution}, ":")))
+	return leadingZeroBits(sum[:]) >= int(challenge.Difficulty)
+}
+
+func leadingZeroBits(hash []byte) int {
+	result := 0
+	for _, b := range hash {
+		if b != 0 {
+			return result + bits.LeadingZeros8(b)
+		}
+		result += 8
+	}
+	return result
+}
@@ -0,0 +1,153 @@
+package pow
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIssuerParse(t *testing.T) {
+	issuer := NewIssuer("secret", time.Minute)
+	challenge, err := issuer.Issue(12)
+	if err != nil {
+		t.Fatalf("Issue() failed: %v", err)
+	}
+	expired, err := NewIssuer("secret", -time.Minute).Issue(12)
+	if err != nil {
+		t.Fatalf("Issue() failed: %v", err)
+	}
+	foreign, err := NewIssuer("other", time.Minute).Issue(12)
+	if err != nil {
+		t.Fatalf("Issue() failed: %v", err)
+	}
+
+	parts := strings.Split(challenge.Token, ".")
+	withPart := func(i int, value string) string {
+		tampered := append([]string{}, parts...)
+		tampered[i] = value
+		return strings.Join(tampered, ".")
+	}
+
+	cases := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"valid", challenge.Token, nil},
+		{"expired", expired.Token, ErrExpired},
+		{"issued with another secret", foreign.Token, ErrInvalid},
+		{"lowered difficulty", withPart(1, "0"), ErrInvalid},
+		{"extended expiration", withPart(2, strconv.FormatInt(challenge.ExpiresAt.Add(time.Hour).Unix(), 10)), ErrInvalid},
+		{"replaced id", withPart(0, "00000000000000000000000000000000"), ErrInvalid},
+		{"missing signature", strings.Join(parts[:3], "."), ErrInvalid},
+		{"empty", "", ErrInvalid},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := issuer.Parse(tc.token)
+			if err != tc.wantErr {
+				t.Fatalf("Parse() error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.ID != challenge.ID || got.Difficulty != challenge.Difficulty || !got.ExpiresAt.Equal(challenge.ExpiresAt) {
+				t.Errorf("Parse() = %+v, want %+v", got, challenge)
+			}
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// token is fixed, so the solution found below and the outcome of every case are deterministic
+	challenge := Challenge{Token: "challenge", Difficulty: 16}
+	solution := solve(t, challenge, "receiver", "100")
+
+	cases := []struct {
+		name      string
+		challenge Challenge
+		receiver  string
+		amount    string
+		solution  string
+		want      bool
+	}{
+		{"solved", challenge, "receiver", "100", solution, true},
+		{"another receiver", challenge, "other", "100", solution, false},
+		{"another amount", challenge, "receiver", "1000", solution, false},
+		{"another challenge", Challenge{Token: "other", Difficulty: 16}, "receiver", "100", solution, false},
+		{"higher difficulty", Challenge{Token: "challenge", Difficulty: 255}, "receiver", "100", solution, false},
+		{"wrong solution", challenge, "receiver", "100", solution + "0", false},
+		{"zero difficulty", Challenge{Token: "challenge"}, "receiver", "100", "anything", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Verify(tc.challenge, tc.receiver, tc.amount, tc.solution); got != tc.want {
+				t.Errorf("Verify(%q, %s, %s, %s) = %v, want %v",
+					tc.challenge.Token, tc.receiver, tc.amount, tc.solution, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	cases := []struct {
+		name string
+		hash []byte
+		want int
+	}{
+		{"no zeros", []byte{0xff, 0x00}, 0},
+		{"within first byte", []byte{0x10, 0xff}, 3},
+		{"whole first byte", []byte{0x00, 0x80}, 8},
+		{"across bytes", []byte{0x00, 0x01}, 15},
+		{"all zeros", []byte{0x00, 0x00}, 16},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := leadingZeroBits(tc.hash); got != tc.want {
+				t.Errorf("leadingZeroBits(%x) = %d, want %d", tc.hash, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestDifficultyFor(t *testing.T) {
+	difficulty := Difficulty{Base: 4, Max: 10, Step: 5}
+
+	cases := []struct {
+		name          string
+		difficulty    Difficulty
+		recentPayouts uint64
+		want          uint8
+	}{
+		{"no payouts", difficulty, 0, 4},
+		{"below step", difficulty, 4, 4},
+		{"one step", difficulty, 5, 5},
+		{"below max", difficulty, 29, 9},
+		{"max", difficulty, 30, 10},
+		{"capped at max", difficulty, 1000, 10},
+		{"fixed difficulty", Difficulty{Base: 8, Max: 8, Step: 1}, 100, 8},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.difficulty.For(tc.recentPayouts); got != tc.want {
+				t.Errorf("For(%d) = %d, want %d", tc.recentPayouts, got, tc.want)
+			}
+		})
+	}
+}
+
+func solve(t *testing.T, challenge Challenge, receiver, amount string) string {
+	for i := 0; i < 1<<24; i++ {
+		solution := strconv.Itoa(i)
+		if Verify(challenge, receiver, amount, solution) {
+			return solution
+		}
+	}
+	t.Fatalf("failed to solve challenge %q", challenge.Token)
+	return ""
+}
@@ -0,0 +1,200 @@
+package cache
+
+import (
+	"context"
+	"faucet-svc/internal/config"
+	"faucet-svc/internal/types"
+	"faucet-svc/internal/types/chains"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
+)
+
+// SignerAddress resolves faucet wallet address for the given chain kind
+type SignerAddress func(kind string) string
+
+// Entry is a faucet balance snapshot of native coin or token on some chain
+type Entry struct {
+	Balance   *big.Int
+	UpdatedAt *time.Time
+	Err       error
+	Stale     bool
+}
+
+type Balances interface {
+	Chain(chain chains.Chain) Entry
+	Token(chain chains.Chain, tokenAddress string) Entry
+	// Refresh re-reads all balances from rpc, calls are fanned out in parallel
+	Refresh(ctx context.Context) error
+	// RefreshAsync schedules re-read of a single balance, e.g. after send
+	RefreshAsync(chain chains.Chain, tokenAddress *string)
+	Run(ctx context.Context)
+}
+
+type target struct {
+	chain        chains.Chain
+	tokenAddress *string
+}
+
+type record struct {
+	balance   *big.Int
+	updatedAt time.Time
+	err       error
+}
+
+type balances struct {
+	log     *logan.Entry
+	cfg     config.BalanceCacheConfig
+	chains  chains.Chains
+	tokens  types.EvmTokens
+	address SignerAddress
+
+	mu      sync.RWMutex
+	records map[string]record
+}
+
+func NewBalances(log *logan.Entry, cfg config.BalanceCacheConfig, chains chains.Chains, tokens types.EvmTokens, address SignerAddress) Balances {
+	return &balances{
+		log:     log.WithField("service", "balance-cache"),
+		cfg:     cfg,
+		chains:  chains,
+		tokens:  tokens,
+		address: address,
+		records: make(map[string]record),
+	}
+}
+
+func (b *balances) Chain(chain chains.Chain) Entry {
+	return b.get(key(chain, nil))
+}
+
+func (b *balances) Token(chain chains.Chain, tokenAddress string) Entry {
+	return b.get(key(chain, &tokenAddress))
+}
+
+func (b *balances) Run(ctx context.Context) {
+	running.WithBackOff(ctx, b.log, "balance-cache", b.Refresh, b.cfg.RefreshInterval, b.cfg.RefreshInterval, 10*b.cfg.RefreshInterval)
+}
+
+func (b *balances) Refresh(ctx context.Context) error {
+	var (
+		wg     sync.WaitGroup
+		failMu sync.Mutex
+		failed []string
+	)
+
+	sem := make(chan struct{}, b.cfg.Concurrency)
+	for _, t := range b.targets() {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(t target) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			if err := b.refresh(t); err != nil {
+				failMu.Lock()
+				failed = append(failed, key(t.chain, t.tokenAddress))
+				failMu.Unlock()
+			}
+		}(t)
+	}
+	wg.Wait()
+
+	if len(failed) != 0 {
+		return errors.From(errors.New("failed to refresh some balances"), logan.F{
+			"failed": strings.Join(failed, ","),
+		})
+	}
+	return nil
+}
+
+func (b *balances) RefreshAsync(chain chains.Chain, tokenAddress *string) {
+	go func() {
+		if err := b.refresh(target{chain: chain, tokenAddress: tokenAddress}); err != nil {
+			b.log.WithError(err).Warn("failed to refresh balance after send")
+		}
+	}()
+}
+
+func (b *balances) targets() []target {
+	var result []target
+	for _, chain := range b.chains {
+		result = append(result, target{chain: chain})
+	}
+
+	for _, token := range b.tokens {
+		tokenAddress := token.Address()
+		for _, chainId := range token.Chains() {
+			chain, ok := b.chains.Get(chainId, "evm")
+			if !ok {
+				continue
+			}
+			result = append(result, target{chain: chain, tokenAddress: &tokenAddress})
+		}
+	}
+	return result
+}
+
+func (b *balances) refresh(t target) error {
+	signerAddress := b.address(t.chain.Kind())
+	balance, err := t.chain.GetBalance(signerAddress, t.tokenAddress)
+	k := key(t.chain, t.tokenAddress)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		// keeping the last known balance, it will be reported as stale
+		rec := b.records[k]
+		rec.err = err
+		b.records[k] = rec
+		return errors.Wrap(err, "failed to get balance", logan.F{
+			"key":            k,
+			"signer_address": signerAddress,
+		})
+	}
+
+	b.records[k] = record{
+		balance:   balance,
+		updatedAt: time.Now().UTC(),
+	}
+	return nil
+}
+
+func (b *balances) get(k string) Entry {
+	b.mu.RLock()
+	rec, ok := b.records[k]
+	b.mu.RUnlock()
+
+	if !ok || rec.balance == nil {
+		return Entry{Err: rec.err, Stale: true}
+	}
+
+	updatedAt := rec.updatedAt
+	return Entry{
+		Balance:   new(big.Int).Set(rec.balance),
+		UpdatedAt: &updatedAt,
+		Err:       rec.err,
+		Stale:     rec.err != nil || time.Since(rec.updatedAt) > b.cfg.StaleAfter,
+	}
+}
+
+func key(chain chains.Chain, tokenAddress *string) string {
+	k := chain.Kind() + ":" + chain.ID()
+	if tokenAddress != nil {
+		k += ":" + strings.ToLower(*tokenAddress)
+	}
+	return k
+}
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types/pg"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/jsonapi"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3"
+)
+
+// Reason codes of 403 rejections, returned in code of the problem
+const (
+	reasonUserBlocked       = "user_blocked"
+	reasonAddressBlocked    = "address_blocked"
+	reasonAddressDenied     = "address_denied"
+	reasonAddressCooldown   = "address_cooldown"
+	reasonReceiverFunneling = "receiver_funneling"
+	reasonAddressBound      = "address_bound"
+	reasonOwnershipRequired = "ownership_required"
+	reasonOwnershipInvalid  = "ownership_invalid"
+	reasonRecipientBalance  = "recipient_balance"
+	reasonPowInvalid        = "pow_invalid"
+	reasonPowUsed           = "pow_used"
+	reasonBatchForbidden    = "batch_forbidden"
+)
+
+// funnelingBlocker is written to created_by of blocks added by funneling detection
+const funnelingBlocker = "funneling-detector"
+
+// checkBlocks returns 403 problem if the user or receiver address is blocked by admin,
+// allowed addresses are not checked against address blocks
+func checkBlocks(r *http.Request, userId, receiver, chainType string) *jsonapi.ErrorObject {
+	targets := []pg.Block{
+		pg.NewBlock(pg.BlockKindUser, "", userId, "", ""),
+	}
+	if !helpers.AddressPolicy(r).IsAllowed(receiver) {
+		targets = append(targets, pg.NewBlock(pg.BlockKindAddress, chainType, receiver, "", ""))
+	}
+
+	for _, target := range targets {
+		block, err := helpers.MasterQ(r).Blocks().
+			FilterByKind(target.Kind).
+			FilterByValue(target.Value).
+			Get()
+		if err != nil {
+			helpers.Log(r).WithError(err).Error("failed to get block")
+			return problems.InternalError()
+		}
+		if block != nil {
+			code := reasonUserBlocked
+			if block.Kind == pg.BlockKindAddress {
+				code = reasonAddressBlocked
+			}
+			return rejection(code, fmt.Sprintf("The %s is blocked", block.Kind), map[string]interface{}{
+				"reason": block.Reason,
+			})
+		}
+	}
+	return nil
+}
+
+// checkAddressPolicy returns 403 problem if receiver is denied by config, received a payout on the chain during cooldown
+// or too many users sent to it recently, limits apply regardless of user sending
+func checkAddressPolicy(r *http.Request, userId, receiver, chainType, chainId string) *jsonapi.ErrorObject {
+	policy := helpers.AddressPolicy(r)
+	if policy.IsAllowed(receiver) {
+		return nil
+	}
+
+	if reason, ok := policy.DenyReason(receiver); ok {
+		return rejection(reasonAddressDenied, "The address is denied", map[string]interface{}{
+			"reason": reason,
+		})
+	}
+
+	now := time.Now().UTC()
+	if policy.Cooldown != 0 {
+		totals, err := helpers.MasterQ(r).Payouts().
+			FilterByReceiver(receiver).
+			FilterByChainType(chainType).
+			FilterByChainID(chainId).
+			FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+			FilterByCreatedAfter(now.Add(-policy.Cooldown)).
+			Totals()
+		if err != nil {
+			helpers.Log(r).WithError(err).Error("failed to get receiver payout totals")
+			return problems.InternalError()
+		}
+		if totals.LastCreatedAt != nil {
+			return rejection(reasonAddressCooldown, "Receiver address cooldown has not passed yet", map[string]interface{}{
+				"next_claim_at": totals.LastCreatedAt.UTC().Add(policy.Cooldown),
+			})
+		}
+	}
+
+	if policy.Funneling.MaxUsers != 0 {
+		return checkFunneling(r, userId, receiver, chainType, now.Add(-policy.Funneling.Window))
+	}
+	return nil
+}
+
+// checkFunneling rejects new users sending to receiver that has already got payouts from too many users since the moment,
+// users who have already sent to it are passed
+func checkFunneling(r *http.Request, userId, receiver, chainType string, since time.Time) *jsonapi.ErrorObject {
+	policy := helpers.AddressPolicy(r)
+	totals, err := helpers.MasterQ(r).Payouts().
+		FilterByReceiver(receiver).
+		FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+		FilterByCreatedAfter(since).
+		Totals()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get receiver payout totals")
+		return problems.InternalError()
+	}
+	if totals.Users < policy.Funneling.MaxUsers {
+		return nil
+	}
+
+	previous, err := helpers.MasterQ(r).Payouts().
+		FilterByReceiver(receiver).
+		FilterByUserID(userId).
+		FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+		FilterByCreatedAfter(since).
+		Get()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get user payout to receiver")
+		return problems.InternalError()
+	}
+	if previous != nil {
+		return nil
+	}
+
+	log := helpers.Log(r).WithFields(logan.F{
+		"receiver": receiver,
+		"users":    totals.Users,
+	})
+	log.Warn("funneling to receiver detected")
+	if policy.Funneling.Block {
+		block := pg.NewBlock(pg.BlockKindAddress, chainType, receiver, "funneling detected", funnelingBlocker)
+		if err := helpers.MasterQ(r).Blocks().Upsert(&block); err != nil {
+			// request is rejected anyway, detection will block the address on the next attempt
+			log.WithError(err).Error("failed to block funneling receiver")
+		}
+	}
+
+	return rejection(reasonReceiverFunneling, "Too many users have sent to the address recently", map[string]interface{}{
+		"users": totals.Users,
+	})
+}
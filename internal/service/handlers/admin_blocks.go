@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/requests"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+func GetBlockList(w http.ResponseWriter, r *http.Request) {
+	request, err := requests.NewGetBlockListRequest(r)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	q := helpers.MasterQ(r).Blocks()
+	if request.FilterKind != nil {
+		q = q.FilterByKind(pg.BlockKind(*request.FilterKind))
+	}
+
+	blocks, err := q.Page(request.OffsetPageParams).Select()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to select blocks")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	blockList := make([]resources.Block, 0, len(blocks))
+	for _, block := range blocks {
+		blockList = append(blockList, newBlock(block))
+	}
+
+	ape.Render(w, resources.BlockListResponse{
+		Data:  blockList,
+		Links: helpers.GetOffsetLinks(r, request.OffsetPageParams),
+	})
+}
+
+// CreateBlock blocks user or receiver address, blocking already blocked one updates the reason
+func CreateBlock(w http.ResponseWriter, r *http.Request) {
+	request, err := requests.NewCreateBlockRequest(r)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	attributes := request.Data.Attributes
+	chainType := ""
+	if attributes.ChainType != nil {
+		chainType = *attributes.ChainType
+	}
+	block := pg.NewBlock(pg.BlockKind(attributes.Kind), chainType, attributes.Value, attributes.Reason, helpers.User(r).Id)
+	if err = helpers.MasterQ(r).Blocks().Upsert(&block); err != nil {
+		helpers.Log(r).WithError(err).Error("failed to upsert block")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	ape.Render(w, resources.BlockResponse{
+		Data: newBlock(block),
+	})
+}
+
+// DeleteBlock unblocks user or address, address is normalized for chain_type query param, so evm one may come in any case
+func DeleteBlock(w http.ResponseWriter, r *http.Request) {
+	kind := pg.BlockKind(chi.URLParam(r, "kind"))
+	value := pg.NormalizeBlockValue(kind, r.URL.Query().Get("chain_type"), chi.URLParam(r, "value"))
+
+	block, err := helpers.MasterQ(r).Blocks().FilterByKind(kind).FilterByValue(value).Get()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get block")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+	if block == nil {
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	if err = helpers.MasterQ(r).Blocks().FilterByKind(kind).FilterByValue(value).Delete(); err != nil {
+		helpers.Log(r).WithError(err).Error("failed to delete block")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func newBlock(block pg.Block) resources.Block {
+	return resources.Block{
+		Key: resources.Key{
+			ID:   string(block.Kind) + ":" + block.Value,
+			Type: resources.BLOCK,
+		},
+		Attributes: resources.BlockAttributes{
+			CreatedAt: block.CreatedAt.UTC(),
+			CreatedBy: block.CreatedBy,
+			Kind:      string(block.Kind),
+			Reason:    block.Reason,
+			Value:     block.Value,
+		},
+	}
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/payouts"
+	"faucet-svc/internal/service/requests"
+	"faucet-svc/internal/types/chains"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+func GetPayoutList(w http.ResponseWriter, r *http.Request) {
+	request, err := requests.NewGetPayoutListRequest(r)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	q := helpers.MasterQ(r).Payouts()
+	if request.FilterUserID != nil {
+		q = q.FilterByUserID(*request.FilterUserID)
+	}
+	if request.FilterStatus != nil {
+		q = q.FilterByStatus(pg.PayoutStatus(*request.FilterStatus))
+	}
+	if request.FilterReceiver != nil {
+		q = q.FilterByReceiver(*request.FilterReceiver)
+	}
+	if request.FilterChainType != nil {
+		q = q.FilterByChainType(*request.FilterChainType)
+	}
+	if request.FilterChainID != nil {
+		q = q.FilterByChainID(*request.FilterChainID)
+	}
+	if request.FilterIP != nil {
+		q = q.FilterByNetwork(*request.FilterIP)
+	}
+
+	payoutRows, err := q.Page(request.OffsetPageParams).Select()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to select payouts")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	payoutList := make([]resources.Payout, 0, len(payoutRows))
+	for _, payout := range payoutRows {
+		payoutList = append(payoutList, newPayout(payout))
+	}
+
+	ape.Render(w, resources.PayoutListResponse{
+		Data:  payoutList,
+		Links: helpers.GetOffsetLinks(r, request.OffsetPageParams),
+	})
+}
+
+// RefundPayout re-sends failed payout to its receiver as a new payout, each payout can be refunded once
+func RefundPayout(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(errors.Wrap(err, "invalid payout id"))...)
+		return
+	}
+
+	original, err := helpers.MasterQ(r).Payouts().FilterByID(id).Get()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get payout")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+	if original == nil {
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+	if original.Status != pg.PayoutStatusFailed {
+		problem := problems.Conflict()
+		problem.Detail = "Only failed payouts can be refunded"
+		ape.RenderErr(w, problem)
+		return
+	}
+
+	chain, ok := helpers.Chains(r).Get(original.ChainId, original.ChainType)
+	if !ok {
+		problem := problems.Conflict()
+		problem.Detail = "Chain of the payout is not configured anymore"
+		ape.RenderErr(w, problem)
+		return
+	}
+
+	tokenAddress := original.TokenAddressPtr()
+	amount := &original.Amount.Int
+	if problem := checkSignerBalance(r, chain, tokenAddress, amount); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+
+	tx, err := chain.Prepare(original.Receiver, amount, tokenAddress)
+	if err != nil {
+		renderProblem(w, chainProblem(r, chain, err, "failed to prepare transaction"))
+		return
+	}
+
+	refund := pg.NewPayout(original.UserId, chain.ID(), chain.Kind(), original.Receiver, tx.Hash(), amount, tokenAddress)
+	refund.RefundOf = &original.ID
+
+	// refund is made by admin, so it's not limited by the user quota
+	err = payouts.Reserve(helpers.MasterQ(r), data.Claim{At: time.Now()}, &refund)
+	if errors.Cause(err) == data.ErrAlreadyRefunded {
+		problem := problems.Conflict()
+		problem.Detail = "Payout is already refunded"
+		ape.RenderErr(w, problem)
+		return
+	}
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to reserve refund payout")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	if problem := broadcastPayout(r, chain, tx, refund); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+
+	helpers.Log(r).WithFields(logan.F{
+		"payout_id": original.ID,
+		"refund_id": refund.ID,
+		"admin_id":  helpers.User(r).Id,
+	}).Info("failed payout refunded")
+
+	refund.Status = broadcastStatus(chain)
+	refund.TxHash = chains.BroadcastHash(tx)
+	ape.Render(w, resources.PayoutResponse{
+		Data: newPayout(refund),
+	})
+}
+
+func newPayout(payout pg.Payout) resources.Payout {
+	attributes := resources.PayoutAttributes{
+		Amount:       payout.Amount.String(),
+		ChainId:      payout.ChainId,
+		ChainType:    payout.ChainType,
+		CreatedAt:    payout.CreatedAt.UTC(),
+		Receiver:     payout.Receiver,
+		Status:       string(payout.Status),
+		TokenAddress: payout.TokenAddressPtr(),
+		TxHash:       payout.TxHash,
+		UpdatedAt:    payout.UpdatedAt.UTC(),
+		UserId:       payout.UserId,
+		Ip:           payout.IP,
+	}
+	if payout.RefundOf != nil {
+		refundOf := strconv.FormatUint(*payout.RefundOf, 10)
+		attributes.RefundOf = &refundOf
+	}
+
+	return resources.Payout{
+		Key:        resources.NewKeyInt64(int64(payout.ID), resources.PAYOUT),
+		Attributes: attributes,
+	}
+}
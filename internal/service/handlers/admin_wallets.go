@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"faucet-svc/internal/service/cache"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types/chains"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+	"strings"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+// GetWalletList shows faucet wallets of every configured chain and token with cached balances
+// and the number of payouts waiting for confirmation
+func GetWalletList(w http.ResponseWriter, r *http.Request) {
+	pending, err := helpers.MasterQ(r).Payouts().FilterByStatus(pg.PayoutStatusPending).Select()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to select pending payouts")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	pendingCount := make(map[string]int64)
+	for _, payout := range pending {
+		pendingCount[walletID(payout.ChainType, payout.ChainId, payout.TokenAddressPtr())]++
+	}
+
+	balances := helpers.BalanceCache(r)
+	signers := helpers.Signers(r)
+	var walletList []resources.Wallet
+	for _, chain := range helpers.Chains(r) {
+		address := helpers.GetSignerAddress(chain.Kind(), signers)
+		walletList = append(walletList, newWallet(chain, address, nil, chain.Decimals(), balances.Chain(chain), pendingCount))
+	}
+
+	for _, token := range helpers.Tokens(r) {
+		for _, chainId := range token.Chains() {
+			chain, ok := helpers.Chains(r).Get(chainId, "evm")
+			if !ok {
+				continue
+			}
+			address := helpers.GetSignerAddress(chain.Kind(), signers)
+			tokenAddress := token.Address()
+			entry := balances.Token(chain, tokenAddress)
+			walletList = append(walletList, newWallet(chain, address, &tokenAddress, token.Decimals(), entry, pendingCount))
+		}
+	}
+
+	ape.Render(w, resources.WalletListResponse{
+		Data: walletList,
+	})
+}
+
+func newWallet(chain chains.Chain, address string, tokenAddress *string, decimals uint8, entry cache.Entry, pendingCount map[string]int64) resources.Wallet {
+	id := walletID(chain.Kind(), chain.ID(), tokenAddress)
+	attributes := resources.WalletAttributes{
+		Address:        address,
+		ChainId:        chain.ID(),
+		ChainType:      chain.Kind(),
+		Error:          entryError(entry),
+		PendingPayouts: pendingCount[id],
+		Stale:          entry.Stale,
+		TokenAddress:   tokenAddress,
+		UpdatedAt:      entry.UpdatedAt,
+	}
+	if entry.Balance != nil {
+		bal := helpers.ToHumanBalance(entry.Balance, decimals)
+		attributes.Balance = &bal
+	}
+
+	return resources.Wallet{
+		Key: resources.Key{
+			ID:   id,
+			Type: resources.WALLET,
+		},
+		Attributes: attributes,
+	}
+}
+
+func walletID(chainType, chainId string, tokenAddress *string) string {
+	id := chainType + ":" + chainId
+	if tokenAddress != nil {
+		id += ":" + strings.ToLower(*tokenAddress)
+	}
+	return id
+}
@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/requests"
+	"faucet-svc/internal/types"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"github.com/go-chi/chi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"net/http"
+	"time"
+)
+
+func GetMyBalanceList(w http.ResponseWriter, r *http.Request) {
+	user := helpers.User(r)
+	if user == nil {
+		helpers.Log(r).Error("request is not authenticated")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+	renderBalanceList(w, r, user.Id, helpers.UserRole(r))
+}
+
+// GetUserBalanceList - roles of other users are known only to auth provider, so quotas of plain user are shown
+func GetUserBalanceList(w http.ResponseWriter, r *http.Request) {
+	renderBalanceList(w, r, chi.URLParam(r, "id"), types.RoleUser)
+}
+
+func renderBalanceList(w http.ResponseWriter, r *http.Request, userId, role string) {
+	request, err := requests.NewGetBalanceListRequest(r)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	balances, err := helpers.BalancesQ(r).
+		FilterByUserID(userId).
+		Page(request.OffsetPageParams).
+		Select()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to select balances")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	now := time.Now().UTC()
+	balanceList := make([]resources.Balance, 0, len(balances))
+	for _, balance := range balances {
+		balanceList = append(balanceList, newBalance(r, balance, role, now))
+	}
+
+	response := resources.BalanceListResponse{
+		Data:  balanceList,
+		Links: helpers.GetOffsetLinks(r, request.OffsetPageParams),
+	}
+
+	ape.Render(w, response)
+}
+
+func newBalance(r *http.Request, balance pg.Balance, role string, now time.Time) resources.Balance {
+	chainType := balance.ChainType
+	chainId := balance.ChainId
+
+	var tokenAddress *string
+	if tknAddr := balance.TokenAddress; tknAddr != "" {
+		tokenAddress = &tknAddr
+	}
+
+	quota := helpers.Quotas(r).Get(role, chainType, chainId, tokenAddress)
+	lastClaimAt := balance.LastClaimAt.UTC()
+	attributes := resources.BalanceAttributes{
+		Amount:       balance.Amount.String(),
+		ChainId:      chainId,
+		ChainType:    chainType,
+		TokenAddress: tokenAddress,
+		LastClaimAt:  lastClaimAt,
+		NextClaimAt:  quota.NextClaimAt(&lastClaimAt, now),
+	}
+
+	if attributes.NextClaimAt != nil {
+		attributes.NextClaimIn = int64(attributes.NextClaimAt.Sub(now).Round(time.Second) / time.Second)
+	}
+
+	if remaining := quota.Remaining(&balance.Amount.Int); remaining != nil {
+		rem := remaining.String()
+		attributes.RemainingQuota = &rem
+	}
+
+	if decimals, ok := helpers.GetDecimals(r, chainType, chainId, tokenAddress); ok {
+		human := helpers.ToHumanBalance(&balance.Amount.Int, decimals)
+		attributes.HumanAmount = &human
+	}
+
+	return resources.Balance{
+		Key:        resources.NewKeyInt64(int64(balance.ID), resources.BALANCE),
+		Attributes: attributes,
+	}
+}
@@ -1,35 +1,20 @@
 package handlers
 
 import (
+	"faucet-svc/internal/service/cache"
 	"faucet-svc/internal/service/helpers"
+	chains2 "faucet-svc/internal/types/chains"
 	"faucet-svc/resources"
 	"gitlab.com/distributed_lab/ape"
-	"gitlab.com/distributed_lab/ape/problems"
-	"math/big"
 	"net/http"
 )
 
 func GetChainList(w http.ResponseWriter, r *http.Request) {
 	chains := helpers.Chains(r)
-	signers := helpers.Signers(r)
+	balances := helpers.BalanceCache(r)
 	var chainList []resources.Chain
 	for _, chain := range chains {
-		signerAddress := helpers.GetSignerAddress(chain.Kind(), signers)
-		balance, err := chain.GetBalance(signerAddress, nil)
-		if err != nil {
-			helpers.Log(r).WithError(err).Errorf("failed to get balance on %s chain, wallet address %s", chain.Kind(), signerAddress)
-			ape.RenderErr(w, problems.InternalError())
-			return
-		}
-
-		chainList = append(chainList, newChain(
-			chain.ID(),
-			chain.Kind(),
-			chain.Name(),
-			chain.NativeToken(),
-			balance,
-			chain.Decimals(),
-		))
+		chainList = append(chainList, newChain(chain, balances.Chain(chain)))
 	}
 
 	response := resources.ChainListResponse{
@@ -39,17 +24,33 @@ func GetChainList(w http.ResponseWriter, r *http.Request) {
 	ape.Render(w, response)
 }
 
-func newChain(id, kind, name, nativeToken string, balance *big.Int, decimals float64) resources.Chain {
-	bal := helpers.ToHumanBalance(balance, decimals)
+func newChain(chain chains2.Chain, entry cache.Entry) resources.Chain {
+	attributes := resources.ChainAttributes{
+		Name:        chain.Name(),
+		NativeToken: chain.NativeToken(),
+		Stale:       entry.Stale,
+		UpdatedAt:   entry.UpdatedAt,
+		Error:       entryError(entry),
+	}
+	if entry.Balance != nil {
+		bal := helpers.ToHumanBalance(entry.Balance, chain.Decimals())
+		attributes.Balance = &bal
+	}
+
 	return resources.Chain{
 		Key: resources.Key{
-			ID:   id,
-			Type: resources.ResourceType(kind),
-		},
-		Attributes: resources.ChainAttributes{
-			Name:        name,
-			Balance:     &bal,
-			NativeToken: nativeToken,
+			ID:   chain.ID(),
+			Type: resources.ResourceType(chain.Kind()),
 		},
+		Attributes: attributes,
+	}
+}
+
+func entryError(entry cache.Entry) *string {
+	if entry.Err == nil {
+		return nil
 	}
+	// rpc errors may contain node urls with api keys, so exposing only generic reason
+	msg := "failed to get balance"
+	return &msg
 }
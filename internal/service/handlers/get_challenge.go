@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+	"time"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+// GetChallenge issues proof of work challenge for anonymous send,
+// difficulty grows with the number of payouts made recently
+func GetChallenge(w http.ResponseWriter, r *http.Request) {
+	cfg := helpers.Anonymous(r).Pow
+	if cfg == nil {
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	totals, err := helpers.MasterQ(r).Payouts().
+		FilterByCreatedAfter(time.Now().UTC().Add(-cfg.Window)).
+		FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+		Totals()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get recent payout totals")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	challenge, err := cfg.Issuer.Issue(cfg.Difficulty.For(totals.Count))
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to issue challenge")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, resources.ChallengeResponse{
+		Data: resources.Challenge{
+			Key: resources.Key{
+				ID:   challenge.ID,
+				Type: resources.CHALLENGE,
+			},
+			Attributes: resources.ChallengeAttributes{
+				Algorithm:  "sha256",
+				Difficulty: int32(challenge.Difficulty),
+				ExpiresAt:  challenge.ExpiresAt,
+				Token:      challenge.Token,
+			},
+		},
+	})
+}
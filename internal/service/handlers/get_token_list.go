@@ -1,20 +1,20 @@
 package handlers
 
 import (
+	"faucet-svc/internal/service/cache"
 	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types"
 	chains2 "faucet-svc/internal/types/chains"
 	"faucet-svc/resources"
 	"gitlab.com/distributed_lab/ape"
-	"gitlab.com/distributed_lab/ape/problems"
-	"math/big"
 	"net/http"
 )
 
 func GetTokenList(w http.ResponseWriter, r *http.Request) {
 
 	chains := helpers.Chains(r)
-	signer := helpers.Signers(r).Evm()
 	tokens := helpers.Tokens(r)
+	balances := helpers.BalanceCache(r)
 
 	var tokenList []resources.Token
 	for _, token := range tokens {
@@ -24,22 +24,11 @@ func GetTokenList(w http.ResponseWriter, r *http.Request) {
 				continue
 			}
 
-			tokenAddress := token.Address()
-			balance, err := chain.GetBalance(signer.Address().String(), &tokenAddress)
-			if err != nil {
-				helpers.Log(r).WithError(err).Errorf("failed to get balance of token %s", token.Address())
-				ape.RenderErr(w, problems.InternalError())
-				return
-			}
-
 			tokenList = append(tokenList, newToken(
-				token.Address(),
-				token.Kind(),
-				token.Name(),
-				token.Symbol(),
-				balance,
-				token.Decimals(),
+				token,
+				balances.Token(chain, token.Address()),
 				chain,
+				balances.Chain(chain),
 			))
 		}
 	}
@@ -51,23 +40,31 @@ func GetTokenList(w http.ResponseWriter, r *http.Request) {
 	ape.Render(w, response)
 }
 
-func newToken(id, kind, name, symbol string, balance *big.Int, decimals float64, chain chains2.Chain) resources.Token {
+func newToken(token types.EvmToken, entry cache.Entry, chain chains2.Chain, chainEntry cache.Entry) resources.Token {
+	attributes := resources.TokenAttributes{
+		Name:      token.Name(),
+		Symbol:    token.Symbol(),
+		Stale:     entry.Stale,
+		UpdatedAt: entry.UpdatedAt,
+		Error:     entryError(entry),
+	}
+	if entry.Balance != nil {
+		bal := helpers.ToHumanBalance(entry.Balance, token.Decimals())
+		attributes.Balance = &bal
+	}
+
 	return resources.Token{
 		Key: resources.Key{
-			ID:   id,
-			Type: resources.ResourceType(kind),
-		},
-		Attributes: resources.TokenAttributes{
-			Name:    name,
-			Balance: helpers.ToHumanBalance(balance, decimals),
-			Symbol:  symbol,
+			ID:   token.Address(),
+			Type: resources.ResourceType(token.Kind()),
 		},
-		Relationships: newRelation(chain),
+		Attributes:    attributes,
+		Relationships: newRelation(chain, chainEntry),
 	}
 }
 
-func newRelation(chain chains2.Chain) *resources.TokenRelationships {
+func newRelation(chain chains2.Chain, entry cache.Entry) *resources.TokenRelationships {
 	return &resources.TokenRelationships{
-		Chain: newChain(chain.ID(), chain.Kind(), chain.Name(), chain.NativeToken(), big.NewInt(0), 0),
+		Chain: newChain(chain, entry),
 	}
 }
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/requests"
+	"faucet-svc/internal/types"
+	"faucet-svc/internal/types/chains"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+
+	"github.com/google/jsonapi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// GetOwnershipNonce issues nonce receiver has to sign to prove address ownership
+func GetOwnershipNonce(w http.ResponseWriter, r *http.Request) {
+	issuer := helpers.Ownership(r)
+	if issuer == nil {
+		ape.RenderErr(w, problems.NotFound())
+		return
+	}
+
+	request, err := requests.NewGetOwnershipNonceRequest(r)
+	if err != nil {
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	address := pg.NormalizeAddress(request.ChainType, request.Address)
+	nonce, err := issuer.Issue(request.ChainType, address)
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to issue ownership nonce")
+		ape.RenderErr(w, problems.InternalError())
+		return
+	}
+
+	ape.Render(w, resources.OwnershipNonceResponse{
+		Data: resources.OwnershipNonce{
+			Key: resources.Key{
+				ID:   nonce.Value,
+				Type: resources.OWNERSHIP_NONCE,
+			},
+			Attributes: resources.OwnershipNonceAttributes{
+				Address:   address,
+				ChainType: request.ChainType,
+				ExpiresAt: nonce.ExpiresAt,
+				Message:   nonce.Message,
+				Nonce:     nonce.Value,
+			},
+		},
+	})
+}
+
+// checkOwnership returns 403 problem if ownership verification is enabled and receiver is bound to another user
+// or is not bound yet and the request has no valid proof. Proven address is bound to the user,
+// anonymous users have to prove ownership on every request and addresses are not bound to them.
+// Account created by the payout with newAccountKey is owned by the holder of the key, so the proof has to be signed by it.
+func checkOwnership(r *http.Request, chain chains.Chain, userId, receiver string, proof *resources.OwnershipProof, newAccountKey *string) *jsonapi.ErrorObject {
+	issuer := helpers.Ownership(r)
+	if issuer == nil {
+		return nil
+	}
+
+	anonymous := helpers.UserRole(r) == types.RoleAnonymous
+	address := pg.NormalizeAddress(chain.Kind(), receiver)
+	if !anonymous {
+		bound, err := helpers.MasterQ(r).Addresses().FilterByChainType(chain.Kind()).FilterByAddress(address).Get()
+		if err != nil {
+			helpers.Log(r).WithError(err).Error("failed to get bound address")
+			return problems.InternalError()
+		}
+		if bound != nil {
+			if bound.UserId == userId {
+				return nil
+			}
+			return rejection(reasonAddressBound, "The address is bound to another user", nil)
+		}
+	}
+
+	if proof == nil {
+		return rejection(reasonOwnershipRequired, "Signature proving address ownership is required", nil)
+	}
+
+	nonce, err := issuer.Parse(proof.Nonce, chain.Kind(), address)
+	if err != nil {
+		return rejection(reasonOwnershipInvalid, "Ownership nonce is invalid or expired", nil)
+	}
+
+	if newAccountKey != nil {
+		if proof.PublicKey == nil || *proof.PublicKey != *newAccountKey {
+			return rejection(reasonOwnershipInvalid, "Ownership of created account has to be proven by its public key", nil)
+		}
+		// public key is validated by request to be supported, so the chain creates accounts
+		err = chain.(chains.AccountCreator).VerifyKeySignature(nonce.Message, proof.Signature, *newAccountKey)
+	} else {
+		err = chain.VerifySignature(receiver, nonce.Message, proof.Signature, proof.PublicKey)
+	}
+	if errors.Cause(err) == chains.ErrInvalidSignature {
+		return rejection(reasonOwnershipInvalid, "Ownership signature is invalid", nil)
+	}
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to verify ownership signature")
+		return problems.InternalError()
+	}
+
+	if anonymous {
+		return nil
+	}
+
+	binding := pg.NewAddress(chain.Kind(), receiver, userId)
+	err = helpers.MasterQ(r).Addresses().Insert(&binding)
+	if errors.Cause(err) == data.ErrAddressBound {
+		// concurrent request has bound the address first, so ownership is decided by that binding
+		return checkOwnership(r, chain, userId, receiver, nil, nil)
+	}
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to bind address")
+		return problems.InternalError()
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types/chains"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/google/jsonapi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+// Codes of problems which are not rejections by faucet policies
+const (
+	codeChainNotFound     = "chain_not_found"
+	codeUnauthenticated   = "unauthenticated"
+	codeInsufficientFunds = "faucet_insufficient_funds"
+	codeInvalidReceiver   = "invalid_receiver"
+	codeRPCUnavailable    = "rpc_unavailable"
+	codeRPCRateLimited    = "rpc_rate_limited"
+	codeNonceConflict     = "nonce_conflict"
+
+	codeClaimCooldown         = "claim_cooldown"
+	codeQuotaExhausted        = "quota_exhausted"
+	codeAddressClaimCooldown  = "address_claim_cooldown"
+	codeAddressQuotaExhausted = "address_quota_exhausted"
+	codePayoutLimit           = "ip_payout_limit"
+	codeIdempotencyConflict   = "idempotency_conflict"
+	codeIdempotencyKeyReused  = "idempotency_key_reused"
+	codeBatchAborted          = "batch_aborted"
+)
+
+// rpcRetryAfter is suggested to clients when chain rpc is temporarily unavailable
+const rpcRetryAfter = 5
+
+// refillRetryAfter is suggested to clients while faucet wallet is refilled by airdrop
+const refillRetryAfter = 10
+
+// metaRetryAfter is meta key of seconds client should wait before retrying
+const metaRetryAfter = "retry_after"
+
+func newProblem(status int, code, detail string, meta map[string]interface{}) *jsonapi.ErrorObject {
+	problem := &jsonapi.ErrorObject{
+		Title:  http.StatusText(status),
+		Status: fmt.Sprintf("%d", status),
+		Code:   code,
+		Detail: detail,
+	}
+	if len(meta) != 0 {
+		problem.Meta = &meta
+	}
+	return problem
+}
+
+// rejection is 403 of faucet policy, code tells which one rejected the request
+func rejection(code, detail string, meta map[string]interface{}) *jsonapi.ErrorObject {
+	return newProblem(http.StatusForbidden, code, detail, meta)
+}
+
+func renderRejection(w http.ResponseWriter, code, detail string, meta map[string]interface{}) {
+	ape.RenderErr(w, rejection(code, detail, meta))
+}
+
+func chainNotFound(chainType, chainId string) *jsonapi.ErrorObject {
+	return newProblem(http.StatusNotFound, codeChainNotFound, fmt.Sprintf("Chain %s:%s is not found", chainType, chainId), nil)
+}
+
+// chainProblem maps typed chain errors to problems, unknown errors are internal ones.
+// Problems of temporary failures have retry_after seconds in meta.
+func chainProblem(r *http.Request, chain chains.Chain, err error, message string) *jsonapi.ErrorObject {
+	log := helpers.Log(r).WithError(err)
+	meta := map[string]interface{}{
+		"chain_type": chain.Kind(),
+		"chain_id":   chain.ID(),
+	}
+
+	var problem *jsonapi.ErrorObject
+	switch {
+	case errors.Is(err, chains.ErrInsufficientFunds):
+		if errors.Is(err, chains.ErrRefilling) {
+			meta[metaRetryAfter] = refillRetryAfter
+		}
+		problem = newProblem(http.StatusServiceUnavailable, codeInsufficientFunds, "Faucet wallet has insufficient funds", meta)
+	case errors.Is(err, chains.ErrInvalidReceiver):
+		problem = newProblem(http.StatusUnprocessableEntity, codeInvalidReceiver, "Network rejected the receiver", meta)
+	case errors.Is(err, chains.ErrNonceConflict):
+		problem = newProblem(http.StatusConflict, codeNonceConflict, "Transaction collided with another one, retry the request", meta)
+	case errors.Is(err, chains.ErrRateLimited):
+		meta[metaRetryAfter] = rpcRetryAfter
+		problem = newProblem(http.StatusServiceUnavailable, codeRPCRateLimited, "Chain rpc is rate limited", meta)
+	case errors.Is(err, chains.ErrRPCUnavailable):
+		meta[metaRetryAfter] = rpcRetryAfter
+		problem = newProblem(http.StatusBadGateway, codeRPCUnavailable, "Chain rpc is unavailable", meta)
+	default:
+		log.Error(message)
+		return problems.InternalError()
+	}
+
+	log.Warn(message)
+	return problem
+}
+
+// renderProblem renders problem of send checks, retry_after of its meta is also set as Retry-After header
+func renderProblem(w http.ResponseWriter, problem *jsonapi.ErrorObject) {
+	if problem.Meta != nil {
+		if retryAfter, ok := (*problem.Meta)[metaRetryAfter].(int); ok {
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+		}
+	}
+	ape.RenderErr(w, problem)
+}
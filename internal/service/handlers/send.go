@@ -1,15 +1,24 @@
 package handlers
 
 import (
-	"faucet-svc/doorman"
+	"faucet-svc/internal/config"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/pow"
 	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/payouts"
 	"faucet-svc/internal/service/requests"
 	"faucet-svc/internal/service/responses"
+	"faucet-svc/internal/types"
+	"faucet-svc/internal/types/chains"
 	"faucet-svc/internal/types/pg"
+	"github.com/google/jsonapi"
 	"gitlab.com/distributed_lab/ape"
 	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"math/big"
+	"net"
 	"net/http"
-	"strings"
+	"time"
 )
 
 func Send(w http.ResponseWriter, r *http.Request) {
@@ -20,68 +29,449 @@ func Send(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	chains := helpers.Chains(r)
-	chain, ok := chains.Get(request.Data.ID, string(request.Data.Type))
+	chain, ok := helpers.Chains(r).Get(request.Data.ID, string(request.Data.Type))
 	if !ok {
-		helpers.Log(r).Error("chain not found")
-		ape.RenderErr(w, problems.NotFound())
+		ape.RenderErr(w, chainNotFound(string(request.Data.Type), request.Data.ID))
 		return
 	}
 
-	signers := helpers.Signers(r)
-	signerAddress := helpers.GetSignerAddress(string(request.Data.Type), signers)
-	tokenAddress := request.Data.Attributes.TokenAddress
-	signerBalance, err := chain.GetBalance(signerAddress, tokenAddress)
-	if err != nil {
-		helpers.Log(r).WithError(err).Errorf("failed to get balance")
-		ape.RenderErr(w, problems.InternalError())
+	user := helpers.User(r)
+	if user == nil {
+		ape.RenderErr(w, newProblem(http.StatusUnauthorized, codeUnauthenticated, "Request is not authenticated", nil))
 		return
 	}
 
-	amount := request.Data.Attributes.Amount
-	if helpers.IsLessOrEq(signerBalance, &amount) {
-		helpers.Log(r).Error("insufficient balance")
-		ape.RenderErr(w, problems.InternalError())
+	if request.IdempotencyKey != nil && replayPayout(w, r, user.Id, request) {
 		return
 	}
 
 	receiver := request.Data.Attributes.To
-	txHash, err := chain.Send(receiver, &amount, tokenAddress)
-	if err != nil {
-		helpers.Log(r).WithError(err).Error("failed to send transaction")
-		ape.RenderErr(w, problems.InternalError())
+	if problem := checkChallenge(r, receiver, request.Amount); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+	if problem := checkBlocks(r, user.Id, receiver, chain.Kind()); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+	if problem := checkAddressPolicy(r, user.Id, receiver, chain.Kind(), chain.ID()); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+	publicKey := request.Data.Attributes.PublicKey
+	if problem := checkOwnership(r, chain, user.Id, receiver, request.Data.Attributes.Ownership, publicKey); problem != nil {
+		renderProblem(w, problem)
 		return
 	}
 
-	decimals := chain.Decimals()
-	if tokenAddress != nil {
-		token, ok := helpers.Tokens(r).Get(strings.ToLower(*tokenAddress))
-		if !ok {
-			helpers.Log(r).Error("not found token")
-			ape.RenderErr(w, problems.InternalError())
+	tokenAddress := request.Data.Attributes.TokenAddress
+	amount, problem := checkRecipientBalance(r, chain, receiver, tokenAddress, publicKey != nil, request.Amount)
+	if problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+	role := helpers.UserRole(r)
+	if problem := checkQuota(r, user.Id, role, chain.Kind(), chain.ID(), tokenAddress, amount); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+	if role == types.RoleAnonymous {
+		if problem := checkAddressQuota(r, receiver, chain.Kind(), chain.ID(), tokenAddress, amount); problem != nil {
+			renderProblem(w, problem)
+			return
+		}
+	}
+	if role != types.RoleAdmin {
+		if problem := checkIPPayouts(r); problem != nil {
+			renderProblem(w, problem)
 			return
 		}
-		decimals = token.Decimals()
 	}
 
-	userId, ok := doorman.GetHeader(r, "User-Id")
-	if !ok {
-		helpers.Log(r).Error("failed to get user id from header")
-		ape.RenderErr(w, problems.InternalError())
+	if problem := checkSignerBalance(r, chain, tokenAddress, amount); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+
+	tx, err := preparePayout(chain, receiver, amount, tokenAddress, publicKey)
+	if err != nil {
+		renderProblem(w, chainProblem(r, chain, err, "failed to prepare transaction"))
 		return
 	}
 
-	humanBalance := helpers.ToHumanBalance(&amount, decimals)
-	balance := pg.NewBalance(userId, chain.ID(), chain.Kind(), humanBalance, tokenAddress)
-	balanceQ := helpers.BalancesQ(r)
-	err = balanceQ.Update(&balance)
+	txHash := tx.Hash()
+	payout := newRequestPayout(r, user.Id, chain, receiver, txHash, amount, tokenAddress)
+	if request.IdempotencyKey != nil {
+		fingerprint := request.Fingerprint()
+		payout.IdempotencyKey = request.IdempotencyKey
+		payout.RequestHash = &fingerprint
+	}
+
+	claim := newClaim(helpers.Quotas(r).Get(role, chain.Kind(), chain.ID(), tokenAddress), time.Now())
+	if challenge := helpers.Challenge(r); challenge != nil {
+		err = payouts.ReserveWithChallenge(helpers.MasterQ(r), *challenge, claim, &payout)
+	} else {
+		err = payouts.Reserve(helpers.MasterQ(r), claim, &payout)
+	}
+	if errors.Cause(err) == data.ErrChallengeUsed {
+		renderRejection(w, reasonPowUsed, "Proof of work challenge is already used", nil)
+		return
+	}
+	if cause := errors.Cause(err); cause == data.ErrQuotaExceeded || cause == data.ErrClaimCooldown {
+		renderProblem(w, claimRejected(r, cause, user.Id, role, chain, tokenAddress, amount))
+		return
+	}
+	if errors.Cause(err) == data.ErrIdempotencyKeyConflict {
+		// concurrent request with the same key has reserved its payout first
+		if !replayPayout(w, r, user.Id, request) {
+			ape.RenderErr(w, newProblem(http.StatusConflict, codeIdempotencyConflict, "Request with the same idempotency key is being processed", nil))
+		}
+		return
+	}
 	if err != nil {
-		helpers.Log(r).WithError(err).Error("failed to update balance")
+		helpers.Log(r).WithError(err).Error("failed to reserve payout")
 		ape.RenderErr(w, problems.InternalError())
 		return
 	}
 
-	response := responses.NewTransactionResponse(txHash)
+	if problem := broadcastPayout(r, chain, tx, payout); problem != nil {
+		renderProblem(w, problem)
+		return
+	}
+
+	response := responses.NewTransactionResponse(chains.BroadcastHash(tx), string(broadcastStatus(chain)), amount.String())
+	w.WriteHeader(200)
+	ape.Render(w, response)
+}
+
+// checkChallenge returns 403 problem if proof of work solution doesn't match receiver and amount,
+// requests not authorized by challenge are passed. The challenge is marked as used on payout reservation.
+func checkChallenge(r *http.Request, receiver string, amount *big.Int) *jsonapi.ErrorObject {
+	challenge := helpers.Challenge(r)
+	if challenge == nil {
+		return nil
+	}
+
+	if !pow.Verify(*challenge, receiver, amount.String(), r.Header.Get("Pow-Solution")) {
+		return rejection(reasonPowInvalid, "Proof of work solution is invalid", nil)
+	}
+	return nil
+}
+
+// checkAddressQuota returns 429 problem if receiver address has exhausted anonymous quota or claims during cooldown,
+// claims are counted by payouts, so addresses can't be topped up via many ips
+func checkAddressQuota(r *http.Request, receiver, chainType, chainId string, tokenAddress *string, amount *big.Int) *jsonapi.ErrorObject {
+	quota := helpers.Anonymous(r).AddressQuotas.Get(types.RoleAnonymous, chainType, chainId, tokenAddress)
+	if quota.MaxAmount == nil && quota.Cooldown == 0 {
+		return nil
+	}
+
+	totals, err := helpers.MasterQ(r).Payouts().
+		FilterByReceiver(receiver).
+		FilterByChainType(chainType).
+		FilterByChainID(chainId).
+		FilterByTokenAddress(quota.TokenAddress).
+		FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+		Totals()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get receiver payout totals")
+		return problems.InternalError()
+	}
+
+	if totals.LastCreatedAt != nil {
+		lastClaimAt := totals.LastCreatedAt.UTC()
+		if next := quota.NextClaimAt(&lastClaimAt, time.Now().UTC()); next != nil {
+			problem := problems.TooManyRequests()
+			problem.Detail = "Receiver address claim cooldown has not passed yet"
+			problem.Code = codeAddressClaimCooldown
+			problem.Meta = &map[string]interface{}{
+				"next_claim_at": next,
+			}
+			return problem
+		}
+	}
+
+	if remaining := quota.Remaining(&totals.Amount.Int); remaining != nil && remaining.Cmp(amount) < 0 {
+		problem := problems.TooManyRequests()
+		problem.Detail = "Requested amount exceeds remaining quota of receiver address"
+		problem.Code = codeAddressQuotaExhausted
+		problem.Meta = &map[string]interface{}{
+			"remaining_quota": remaining.String(),
+		}
+		return problem
+	}
+	return nil
+}
+
+// checkRecipientBalance returns 403 problem if receiver already holds enough, otherwise returns amount to be sent,
+// which is less than requested if receiver is only topped up to the target balance. Account created by the payout holds nothing.
+func checkRecipientBalance(r *http.Request, chain chains.Chain, receiver string, tokenAddress *string, newAccount bool, requested *big.Int) (*big.Int, *jsonapi.ErrorObject) {
+	policy := helpers.RecipientBalances(r).Get(chain.Kind(), chain.ID(), tokenAddress)
+	if policy.IsEmpty() {
+		return requested, nil
+	}
+
+	balance := big.NewInt(0)
+	if !newAccount {
+		var err error
+		balance, err = chain.GetBalance(receiver, tokenAddress)
+		if err != nil {
+			return nil, chainProblem(r, chain, err, "failed to get receiver balance")
+		}
+	}
+
+	amount := policy.Amount(balance, requested)
+	if amount == nil {
+		meta := map[string]interface{}{
+			"balance": balance.String(),
+		}
+		if policy.MaxBalance != nil {
+			meta["max_balance"] = policy.MaxBalance.String()
+		}
+		if policy.TopUpTo != nil {
+			meta["top_up_to"] = policy.TopUpTo.String()
+		}
+		return nil, rejection(reasonRecipientBalance, "Receiver already holds enough", meta)
+	}
+	return amount, nil
+}
+
+// checkIPPayouts returns 429 problem if client ip or its subnet has got too many payouts recently, regardless of users
+func checkIPPayouts(r *http.Request) *jsonapi.ErrorObject {
+	ip := net.ParseIP(helpers.RemoteIP(r))
+	if ip == nil {
+		return nil
+	}
+
+	limits := helpers.IPLimits(r)
+	now := time.Now().UTC()
+	scopes := []struct {
+		name    string
+		network *net.IPNet
+		limit   config.RateLimit
+	}{
+		{"ip", limits.Network(ip), limits.Payouts.IP},
+		{"subnet", limits.Subnet(ip), limits.Payouts.Subnet},
+	}
+
+	for _, scope := range scopes {
+		if scope.limit.Limit == 0 {
+			continue
+		}
+
+		totals, err := helpers.MasterQ(r).Payouts().
+			FilterByNetwork(scope.network.String()).
+			FilterByStatus(pg.PayoutStatusPending, pg.PayoutStatusSent).
+			FilterByCreatedAfter(now.Add(-scope.limit.Window)).
+			Totals()
+		if err != nil {
+			helpers.Log(r).WithError(err).Error("failed to get ip payout totals")
+			return problems.InternalError()
+		}
+		if totals.Count >= scope.limit.Limit {
+			problem := problems.TooManyRequests()
+			problem.Detail = "Too many payouts to the " + scope.name
+			problem.Code = codePayoutLimit
+			problem.Meta = &map[string]interface{}{
+				"scope": scope.name,
+			}
+			return problem
+		}
+	}
+	return nil
+}
+
+// checkSignerBalance returns 503 problem if faucet wallet can't cover the amount
+func checkSignerBalance(r *http.Request, chain chains.Chain, tokenAddress *string, amount *big.Int) *jsonapi.ErrorObject {
+	if airdropper, ok := chain.(chains.Airdropper); ok && airdropper.Airdrops() {
+		// network funds the payout, failures are reported by the chain on prepare
+		return nil
+	}
+
+	signerAddress := helpers.GetSignerAddress(chain.Kind(), helpers.Signers(r))
+	signerBalance, err := chain.GetBalance(signerAddress, tokenAddress)
+	if err != nil {
+		return chainProblem(r, chain, err, "failed to get faucet balance")
+	}
+
+	if helpers.IsLessOrEq(signerBalance, amount) {
+		helpers.Log(r).Warn("insufficient faucet balance")
+		return newProblem(http.StatusServiceUnavailable, codeInsufficientFunds, "Faucet wallet has insufficient funds", map[string]interface{}{
+			"chain_type": chain.Kind(),
+			"chain_id":   chain.ID(),
+		})
+	}
+	return nil
+}
+
+// preparePayout prepares transaction paying the receiver, receiver account is created with the public key if it's set
+func preparePayout(chain chains.Chain, receiver string, amount *big.Int, tokenAddress, publicKey *string) (chains.Tx, error) {
+	if publicKey != nil {
+		// public key is validated by request to be supported, so the chain creates accounts
+		return chain.(chains.AccountCreator).PrepareAccount(receiver, *publicKey, amount)
+	}
+	return chain.Prepare(receiver, amount, tokenAddress)
+}
+
+// newRequestPayout creates payout requested by the user, client ip is recorded for per ip limits
+func newRequestPayout(r *http.Request, userId string, chain chains.Chain, receiver, txHash string, amount *big.Int, tokenAddress *string) pg.Payout {
+	payout := pg.NewPayout(userId, chain.ID(), chain.Kind(), receiver, txHash, amount, tokenAddress)
+	if ip := net.ParseIP(helpers.RemoteIP(r)); ip != nil {
+		payoutIP := ip.String()
+		payout.IP = &payoutIP
+	}
+	return payout
+}
+
+// broadcastPayout sends reserved transaction of the payouts, on failure chain problem is returned and the payouts are released
+// if the network rejected the transaction, otherwise they are left pending for reconciler as the transaction may be delivered.
+// Transaction may pay several payouts of the same currency.
+func broadcastPayout(r *http.Request, chain chains.Chain, tx chains.Tx, reserved ...pg.Payout) *jsonapi.ErrorObject {
+	log := helpers.Log(r).WithField("tx_hash", tx.Hash())
+	if err := chain.Broadcast(tx); err != nil {
+		if chains.IsRejected(err) {
+			for _, payout := range reserved {
+				if err := payouts.Release(helpers.MasterQ(r), payout); err != nil {
+					// reconciler will release it once the transaction is known to be undelivered
+					log.WithError(err).WithField("payout_id", payout.ID).Error("failed to release payout")
+				}
+			}
+		}
+		return chainProblem(r.WithContext(helpers.CtxLog(log)(r.Context())), chain, err, "failed to send transaction")
+	}
+	helpers.BalanceCache(r).RefreshAsync(chain, reserved[0].TokenAddressPtr())
+	if hashed, ok := tx.(chains.NetworkHashTx); ok {
+		for _, payout := range reserved {
+			if err := payouts.UpdateTxHash(helpers.MasterQ(r), payout, hashed.NetworkHash()); err != nil {
+				// payout keeps placeholder hash, so reconciler releases it
+				log.WithError(err).WithField("payout_id", payout.ID).Error("failed to update payout transaction hash")
+			}
+		}
+	}
+	if broadcastStatus(chain) == pg.PayoutStatusPending {
+		// transaction is only submitted, reconciler confirms the payouts once it's executed
+		return nil
+	}
+
+	for _, payout := range reserved {
+		if err := payouts.Confirm(helpers.MasterQ(r), payout); err != nil {
+			// funds are already sent, so not failing the request, reconciler will confirm the payout
+			log.WithError(err).WithField("payout_id", payout.ID).Error("failed to confirm payout")
+		}
+	}
+	return nil
+}
+
+// broadcastStatus is status of payouts right after their transaction is broadcast
+func broadcastStatus(chain chains.Chain) pg.PayoutStatus {
+	if _, ok := chain.(chains.AsyncBroadcaster); ok {
+		return pg.PayoutStatusPending
+	}
+	return pg.PayoutStatusSent
+}
+
+// replayPayout renders the payout previously made with the same idempotency key, if any.
+// Reusing the key with another request body is rejected with 422.
+func replayPayout(w http.ResponseWriter, r *http.Request, userId string, request requests.CreateSendRequest) bool {
+	payout, err := helpers.MasterQ(r).Payouts().
+		FilterByUserID(userId).
+		FilterByIdempotencyKey(*request.IdempotencyKey).
+		Get()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get payout by idempotency key")
+		ape.RenderErr(w, problems.InternalError())
+		return true
+	}
+	if payout == nil {
+		return false
+	}
+
+	if payout.RequestHash == nil || *payout.RequestHash != request.Fingerprint() {
+		ape.RenderErr(w, newProblem(http.StatusUnprocessableEntity, codeIdempotencyKeyReused, "Idempotency key was already used with another request", nil))
+		return true
+	}
+
+	response := responses.NewTransactionResponse(payout.TxHash, string(payout.Status), payout.Amount.String())
 	w.WriteHeader(200)
 	ape.Render(w, response)
+	return true
+}
+
+// checkQuota returns 429 problem if user has exhausted quota or claims during cooldown
+func checkQuota(r *http.Request, userId, role, chainType, chainId string, tokenAddress *string, amount *big.Int) *jsonapi.ErrorObject {
+	quota := helpers.Quotas(r).Get(role, chainType, chainId, tokenAddress)
+	if quota.MaxAmount == nil && quota.Cooldown == 0 {
+		return nil
+	}
+
+	claimed, err := helpers.BalancesQ(r).
+		FilterByUserID(userId).
+		FilterByChainID(chainId).
+		FilterByChainType(chainType).
+		FilterByTokenAddress(quota.TokenAddress).
+		Get()
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to get claimed balance")
+		return problems.InternalError()
+	}
+	if claimed == nil {
+		claimed = &pg.Balance{}
+	}
+
+	now := time.Now().UTC()
+	if claimed.ID != 0 {
+		lastClaimAt := claimed.LastClaimAt.UTC()
+		if next := quota.NextClaimAt(&lastClaimAt, now); next != nil {
+			problem := problems.TooManyRequests()
+			problem.Detail = "Claim cooldown has not passed yet"
+			problem.Code = codeClaimCooldown
+			problem.Meta = &map[string]interface{}{
+				"next_claim_at": next,
+			}
+			return problem
+		}
+	}
+
+	if remaining := quota.Remaining(&claimed.Amount.Int); remaining != nil && remaining.Cmp(amount) < 0 {
+		problem := problems.TooManyRequests()
+		problem.Detail = "Requested amount exceeds remaining quota"
+		problem.Code = codeQuotaExhausted
+		problem.Meta = &map[string]interface{}{
+			"remaining_quota": remaining.String(),
+		}
+		return problem
+	}
+	return nil
+}
+
+// newClaim limits payouts reserved at the time by max amount and cooldown of the quota
+func newClaim(quota types.Quota, at time.Time) data.Claim {
+	return data.Claim{
+		At:        at,
+		MaxAmount: quota.MaxAmount,
+		Cooldown:  quota.Cooldown,
+	}
+}
+
+// claimRejected returns 429 problem for payout which didn't fit into the quota on reservation, as concurrent request
+// has claimed it after the check. The quota is checked again to report its current state.
+func claimRejected(r *http.Request, cause error, userId, role string, chain chains.Chain, tokenAddress *string, amount *big.Int) *jsonapi.ErrorObject {
+	if problem := checkQuota(r, userId, role, chain.Kind(), chain.ID(), tokenAddress, amount); problem != nil {
+		return problem
+	}
+	// concurrent claim has been released since
+	return reservationRejected(cause)
+}
+
+// reservationRejected returns 429 problem for data.ErrQuotaExceeded or data.ErrClaimCooldown of payout reservation
+func reservationRejected(cause error) *jsonapi.ErrorObject {
+	problem := problems.TooManyRequests()
+	if cause == data.ErrClaimCooldown {
+		problem.Detail = "Claim cooldown has not passed yet"
+		problem.Code = codeClaimCooldown
+	} else {
+		problem.Detail = "Requested amount exceeds remaining quota"
+		problem.Code = codeQuotaExhausted
+	}
+	return problem
 }
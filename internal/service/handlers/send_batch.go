@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"encoding/json"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/payouts"
+	"faucet-svc/internal/service/requests"
+	"faucet-svc/internal/types"
+	"faucet-svc/internal/types/chains"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/jsonapi"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+// batchItem is a send of the batch, it is failed as soon as any check or broadcast returns a problem
+type batchItem struct {
+	request requests.CreateSendRequest
+	chain   chains.Chain
+	amount  *big.Int
+	txHash  string
+	problem *jsonapi.ErrorObject
+}
+
+func (i *batchItem) tokenAddress() *string {
+	return i.request.Data.Attributes.TokenAddress
+}
+
+func (i *batchItem) publicKey() *string {
+	return i.request.Data.Attributes.PublicKey
+}
+
+// SendBatch pays sends of the batch with sequential transactions per chain, every send is checked
+// the same way as a single one and fails on its own, so partial failures are reported per item
+func SendBatch(w http.ResponseWriter, r *http.Request) {
+	user := helpers.User(r)
+	if user == nil {
+		ape.RenderErr(w, newProblem(http.StatusUnauthorized, codeUnauthenticated, "Request is not authenticated", nil))
+		return
+	}
+
+	role := helpers.UserRole(r)
+	if !helpers.Batch(r).Allows(role) {
+		renderRejection(w, reasonBatchForbidden, "Batch sends are not allowed for the user", nil)
+		return
+	}
+
+	request, err := requests.NewCreateSendBatchRequest(r)
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("invalid request")
+		ape.RenderErr(w, problems.BadRequest(err)...)
+		return
+	}
+
+	if role != types.RoleAdmin {
+		if problem := checkIPPayouts(r); problem != nil {
+			renderProblem(w, problem)
+			return
+		}
+	}
+
+	items := make([]*batchItem, len(request.Items))
+	claimed := make(map[string]*big.Int)
+	for i, send := range request.Items {
+		items[i] = &batchItem{request: send}
+		checkBatchItem(r, user.Id, role, items[i], claimed)
+	}
+
+	// the whole batch is a single claim for cooldowns
+	claimedAt := time.Now()
+	for _, group := range groupBatchItems(items) {
+		sendBatchGroup(r, user.Id, claimedAt, group)
+	}
+
+	response := resources.SendResultListResponse{
+		Data: make([]resources.SendResult, 0, len(items)),
+	}
+	for i, item := range items {
+		response.Data = append(response.Data, newSendResult(i, item))
+	}
+	ape.Render(w, response)
+}
+
+// checkBatchItem runs checks of a single send, amounts of previous sends of the batch are counted to quotas,
+// while the whole batch counts as a single claim for cooldowns
+func checkBatchItem(r *http.Request, userId, role string, item *batchItem, claimed map[string]*big.Int) {
+	send := item.request.Data
+	chain, ok := helpers.Chains(r).Get(send.ID, string(send.Type))
+	if !ok {
+		item.problem = chainNotFound(string(send.Type), send.ID)
+		return
+	}
+	item.chain = chain
+
+	receiver := send.Attributes.To
+	if item.problem = checkBlocks(r, userId, receiver, chain.Kind()); item.problem != nil {
+		return
+	}
+	if item.problem = checkAddressPolicy(r, userId, receiver, chain.Kind(), chain.ID()); item.problem != nil {
+		return
+	}
+	if item.problem = checkOwnership(r, chain, userId, receiver, send.Attributes.Ownership, item.publicKey()); item.problem != nil {
+		return
+	}
+
+	amount, problem := checkRecipientBalance(r, chain, receiver, item.tokenAddress(), item.publicKey() != nil, item.request.Amount)
+	if problem != nil {
+		item.problem = problem
+		return
+	}
+
+	key := batchCurrencyKey(chain, item.tokenAddress())
+	total := new(big.Int).Set(amount)
+	if previous, ok := claimed[key]; ok {
+		total.Add(total, previous)
+	}
+	if item.problem = checkQuota(r, userId, role, chain.Kind(), chain.ID(), item.tokenAddress(), total); item.problem != nil {
+		return
+	}
+
+	claimed[key] = total
+	item.amount = amount
+}
+
+// groupBatchItems groups items passed the checks by chain in order of their first item
+func groupBatchItems(items []*batchItem) [][]*batchItem {
+	var groups [][]*batchItem
+	chainGroups := make(map[string]int)
+	for _, item := range items {
+		if item.problem != nil {
+			continue
+		}
+
+		key := item.chain.Kind() + ":" + item.chain.ID()
+		group, ok := chainGroups[key]
+		if !ok {
+			group = len(groups)
+			chainGroups[key] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], item)
+	}
+	return groups
+}
+
+// sendBatchGroup pays items of the same chain, chains implementing chains.Batcher prepare all transactions at once,
+// so once a transaction fails the following ones are not broadcast to not leave gaps in nonces
+func sendBatchGroup(r *http.Request, userId string, claimedAt time.Time, items []*batchItem) {
+	chain := items[0].chain
+	items = checkBatchSignerBalance(r, chain, items)
+	if len(items) == 0 {
+		return
+	}
+
+	batcher, ok := chain.(chains.Batcher)
+	if !ok {
+		for _, item := range items {
+			tx, err := preparePayout(chain, item.request.Data.Attributes.To, item.amount, item.tokenAddress(), item.publicKey())
+			if err != nil {
+				item.problem = chainProblem(r, chain, err, "failed to prepare transaction")
+				continue
+			}
+			broadcastBatchTx(r, userId, claimedAt, chain, tx, []*batchItem{item})
+		}
+		return
+	}
+
+	transfers := make([]chains.Transfer, 0, len(items))
+	for _, item := range items {
+		transfers = append(transfers, chains.Transfer{
+			To:           item.request.Data.Attributes.To,
+			Amount:       item.amount,
+			TokenAddress: item.tokenAddress(),
+		})
+	}
+
+	txs, err := batcher.PrepareBatch(transfers)
+	if err != nil {
+		failBatchItems(items, chainProblem(r, chain, err, "failed to prepare transactions"))
+		return
+	}
+
+	aborted := false
+	for _, paid := range groupByTx(txs, items) {
+		if aborted {
+			failBatchItems(paid.items, newProblem(http.StatusFailedDependency, codeBatchAborted, "Previous transaction of the chain has failed", nil))
+			continue
+		}
+		aborted = !broadcastBatchTx(r, userId, claimedAt, chain, paid.tx, paid.items)
+	}
+}
+
+// checkBatchSignerBalance fails items of currencies faucet wallet can't cover in total, the rest items are returned
+func checkBatchSignerBalance(r *http.Request, chain chains.Chain, items []*batchItem) []*batchItem {
+	var currencies []string
+	totals := make(map[string]*big.Int)
+	tokens := make(map[string]*string)
+	for _, item := range items {
+		key := batchCurrencyKey(chain, item.tokenAddress())
+		if _, ok := totals[key]; !ok {
+			currencies = append(currencies, key)
+			totals[key] = big.NewInt(0)
+			tokens[key] = item.tokenAddress()
+		}
+		totals[key].Add(totals[key], item.amount)
+	}
+
+	covered := make(map[string]bool)
+	for _, key := range currencies {
+		problem := checkSignerBalance(r, chain, tokens[key], totals[key])
+		if problem == nil {
+			covered[key] = true
+			continue
+		}
+		for _, item := range items {
+			if batchCurrencyKey(chain, item.tokenAddress()) == key {
+				item.problem = problem
+			}
+		}
+	}
+
+	result := make([]*batchItem, 0, len(items))
+	for _, item := range items {
+		if covered[batchCurrencyKey(chain, item.tokenAddress())] {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+type batchTx struct {
+	tx    chains.Tx
+	items []*batchItem
+}
+
+// groupByTx groups items paid by the same transaction, transactions keep the order they were prepared in
+func groupByTx(txs []chains.Tx, items []*batchItem) []batchTx {
+	var result []batchTx
+	positions := make(map[string]int)
+	for i, tx := range txs {
+		position, ok := positions[tx.Hash()]
+		if !ok {
+			position = len(result)
+			positions[tx.Hash()] = position
+			result = append(result, batchTx{tx: tx})
+		}
+		result[position].items = append(result[position].items, items[i])
+	}
+	return result
+}
+
+// broadcastBatchTx reserves payouts of the items and broadcasts transaction paying them
+func broadcastBatchTx(r *http.Request, userId string, claimedAt time.Time, chain chains.Chain, tx chains.Tx, items []*batchItem) bool {
+	txHash := tx.Hash()
+	reserved := make([]pg.Payout, len(items))
+	pointers := make([]*pg.Payout, len(items))
+	for i, item := range items {
+		reserved[i] = newRequestPayout(r, userId, chain, item.request.Data.Attributes.To, txHash, item.amount, item.tokenAddress())
+		pointers[i] = &reserved[i]
+	}
+
+	// items of a transaction are of the same currency, so they share the quota
+	quota := helpers.Quotas(r).Get(helpers.UserRole(r), chain.Kind(), chain.ID(), items[0].tokenAddress())
+	err := payouts.Reserve(helpers.MasterQ(r), newClaim(quota, claimedAt), pointers...)
+	if cause := errors.Cause(err); cause == data.ErrQuotaExceeded || cause == data.ErrClaimCooldown {
+		// quota is not checked again, as previous transactions of the batch have already claimed it
+		failBatchItems(items, reservationRejected(cause))
+		return false
+	}
+	if err != nil {
+		helpers.Log(r).WithError(err).Error("failed to reserve payouts")
+		failBatchItems(items, problems.InternalError())
+		return false
+	}
+
+	if problem := broadcastPayout(r, chain, tx, reserved...); problem != nil {
+		failBatchItems(items, problem)
+		return false
+	}
+
+	for _, item := range items {
+		item.txHash = chains.BroadcastHash(tx)
+	}
+	return true
+}
+
+func failBatchItems(items []*batchItem, problem *jsonapi.ErrorObject) {
+	for _, item := range items {
+		item.problem = problem
+	}
+}
+
+func batchCurrencyKey(chain chains.Chain, tokenAddress *string) string {
+	key := chain.Kind() + ":" + chain.ID()
+	if tokenAddress != nil {
+		key += ":" + strings.ToLower(*tokenAddress)
+	}
+	return key
+}
+
+func newSendResult(index int, item *batchItem) resources.SendResult {
+	attributes := resources.SendResultAttributes{
+		To: item.request.Data.Attributes.To,
+	}
+	if item.problem != nil {
+		attributes.Status = string(pg.PayoutStatusFailed)
+		attributes.Error = newSendResultError(item.problem)
+	} else {
+		attributes.Status = string(broadcastStatus(item.chain))
+		amount := item.amount.String()
+		attributes.Amount = &amount
+		attributes.TxHash = &item.txHash
+	}
+
+	return resources.SendResult{
+		Key: resources.Key{
+			ID:   strconv.Itoa(index),
+			Type: resources.SEND_RESULT,
+		},
+		Attributes: attributes,
+	}
+}
+
+// newSendResultError converts problem of a send check to error of the batch item
+func newSendResultError(problem *jsonapi.ErrorObject) *resources.SendResultError {
+	result := &resources.SendResultError{
+		Title:  problem.Title,
+		Status: problem.Status,
+	}
+	if problem.Code != "" {
+		result.Code = &problem.Code
+	}
+	if problem.Detail != "" {
+		result.Detail = &problem.Detail
+	}
+	if problem.Meta != nil {
+		// meta of send problems holds only plain values, so it is always marshaled
+		meta, _ := json.Marshal(*problem.Meta)
+		raw := json.RawMessage(meta)
+		result.Meta = &raw
+	}
+	return result
+}
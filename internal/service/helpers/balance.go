@@ -1,8 +1,11 @@
 package helpers
 
 import (
-	"math"
 	"math/big"
+	"net/http"
+	"strings"
+
+	"gitlab.com/distributed_lab/logan/v3/errors"
 )
 
 func IsLessOrEq(x, y *big.Int) bool {
@@ -12,7 +15,81 @@ func IsLessOrEq(x, y *big.Int) bool {
 	return false
 }
 
-func ToHumanBalance(amount *big.Int, decimals float64) float64 {
-	x, _ := new(big.Float).SetInt(amount).Float64()
-	return x / math.Pow(10, decimals)
+// ToHumanBalance formats amount in base units as exact decimal string,
+// e.g. 1500000 with 6 decimals is "1.5"
+func ToHumanBalance(amount *big.Int, decimals uint8) string {
+	sign := ""
+	digits := amount.String()
+	if amount.Sign() < 0 {
+		sign = "-"
+		digits = digits[1:]
+	}
+
+	if decimals == 0 {
+		return sign + digits
+	}
+
+	if pad := int(decimals) + 1 - len(digits); pad > 0 {
+		digits = strings.Repeat("0", pad) + digits
+	}
+
+	point := len(digits) - int(decimals)
+	fraction := strings.TrimRight(digits[point:], "0")
+	if fraction == "" {
+		return sign + digits[:point]
+	}
+	return sign + digits[:point] + "." + fraction
+}
+
+// FromHumanBalance parses non-negative decimal string into amount in base units,
+// values having more fractional digits than decimals are rejected
+func FromHumanBalance(value string, decimals uint8) (*big.Int, error) {
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" && fraction == "" {
+		return nil, errors.New("empty amount")
+	}
+	if hasPoint && fraction == "" {
+		return nil, errors.New("missing fractional part")
+	}
+	if !isDigits(whole) || !isDigits(fraction) {
+		return nil, errors.New("must be a decimal number")
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > int(decimals) {
+		return nil, errors.Errorf("must have at most %d fractional digits", decimals)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", int(decimals)-len(fraction))
+	amount, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, errors.New("must be a decimal number")
+	}
+	return amount, nil
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// GetDecimals returns decimals of configured chain or, if token address is set, of its token
+func GetDecimals(r *http.Request, chainType, chainId string, tokenAddress *string) (uint8, bool) {
+	if tokenAddress != nil {
+		token, ok := Tokens(r).Find(chainType, chainId, *tokenAddress)
+		if !ok {
+			return 0, false
+		}
+		return token.Decimals(), true
+	}
+
+	chain, ok := Chains(r).Get(chainId, chainType)
+	if !ok {
+		return 0, false
+	}
+	return chain.Decimals(), true
 }
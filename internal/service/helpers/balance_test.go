@@ -0,0 +1,85 @@
+package helpers
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestToHumanBalance(t *testing.T) {
+	cases := []struct {
+		name     string
+		amount   string
+		decimals uint8
+		want     string
+	}{
+		{"whole", "1000000", 6, "1"},
+		{"fraction", "1500000", 6, "1.5"},
+		{"trailing zeros trimmed", "1230000", 6, "1.23"},
+		{"less than one", "1", 6, "0.000001"},
+		{"zero", "0", 6, "0"},
+		{"negative", "-1500000", 6, "-1.5"},
+		{"negative less than one", "-5", 3, "-0.005"},
+		{"zero decimals", "42", 0, "42"},
+		{"negative zero decimals", "-42", 0, "-42"},
+		{"wei", "1000000000000000001", 18, "1.000000000000000001"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			amount, ok := new(big.Int).SetString(tc.amount, 10)
+			if !ok {
+				t.Fatalf("invalid amount %s", tc.amount)
+			}
+			if got := ToHumanBalance(amount, tc.decimals); got != tc.want {
+				t.Errorf("ToHumanBalance(%s, %d) = %s, want %s", tc.amount, tc.decimals, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestFromHumanBalance(t *testing.T) {
+	cases := []struct {
+		name     string
+		value    string
+		decimals uint8
+		want     string
+		wantErr  bool
+	}{
+		{"whole", "1", 6, "1000000", false},
+		{"fraction", "1.5", 6, "1500000", false},
+		{"leading point", ".5", 6, "500000", false},
+		{"all fraction digits", "0.000001", 6, "1", false},
+		{"trailing zeros over decimals", "1.5000000", 6, "1500000", false},
+		{"zero", "0", 6, "0", false},
+		{"zero decimals", "42", 0, "42", false},
+		{"zero decimals with zero fraction", "42.0", 0, "42", false},
+		{"zero decimals with fraction", "42.5", 0, "", true},
+		{"too many fraction digits", "0.0000001", 6, "", true},
+		{"no rounding", "1.0000015", 6, "", true},
+		{"negative", "-1", 6, "", true},
+		{"plus sign", "+1", 6, "", true},
+		{"empty", "", 6, "", true},
+		{"point only", ".", 6, "", true},
+		{"missing fraction", "1.", 6, "", true},
+		{"exponent", "1e6", 6, "", true},
+		{"two points", "1.2.3", 6, "", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := FromHumanBalance(tc.value, tc.decimals)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("FromHumanBalance(%q, %d) = %s, want error", tc.value, tc.decimals, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("FromHumanBalance(%q, %d) failed: %v", tc.value, tc.decimals, err)
+			}
+			if got.String() != tc.want {
+				t.Errorf("FromHumanBalance(%q, %d) = %s, want %s", tc.value, tc.decimals, got, tc.want)
+			}
+		})
+	}
+}
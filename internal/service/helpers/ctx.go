@@ -2,11 +2,16 @@ package helpers
 
 import (
 	"context"
-	"faucet-svc/doorman"
+	"faucet-svc/internal/auth"
 	"faucet-svc/internal/config"
 	"faucet-svc/internal/data"
+	"faucet-svc/internal/ownership"
+	"faucet-svc/internal/pow"
+	"faucet-svc/internal/service/cache"
+	"faucet-svc/internal/service/ratelimit"
 	"faucet-svc/internal/types"
 	"faucet-svc/internal/types/chains"
+	"faucet-svc/resources"
 	"net/http"
 
 	"gitlab.com/distributed_lab/logan/v3"
@@ -19,8 +24,21 @@ const (
 	chainerCtxKey
 	signererCtxKey
 	tokensCtxKey
-	doormanConnectorCtxKey
-	BalancesQCtxKey
+	authenticatorCtxKey
+	masterQCtxKey
+	balanceCacheCtxKey
+	quotasCtxKey
+	adminsCtxKey
+	userCtxKey
+	anonymousCtxKey
+	challengeCtxKey
+	addressPolicyCtxKey
+	ipLimitsCtxKey
+	requestLimiterCtxKey
+	clientIPCtxKey
+	ownershipCtxKey
+	recipientBalancesCtxKey
+	batchCtxKey
 )
 
 func CtxLog(entry *logan.Entry) func(context.Context) context.Context {
@@ -63,21 +81,156 @@ func Tokens(r *http.Request) types.EvmTokens {
 	return r.Context().Value(tokensCtxKey).(types.EvmTokens)
 }
 
-func CtxDoormanConnector(entry doorman.Connector) func(context.Context) context.Context {
+func CtxAuthenticator(entry auth.Authenticator) func(context.Context) context.Context {
 	return func(ctx context.Context) context.Context {
-		return context.WithValue(ctx, doormanConnectorCtxKey, entry)
+		return context.WithValue(ctx, authenticatorCtxKey, entry)
 	}
 }
-func DoormanConnector(r *http.Request) doorman.Connector {
-	return r.Context().Value(doormanConnectorCtxKey).(doorman.Connector)
+func Authenticator(r *http.Request) auth.Authenticator {
+	return r.Context().Value(authenticatorCtxKey).(auth.Authenticator)
 }
 
-func CtxBalancesQ(entry data.BalancesQ) func(context.Context) context.Context {
+func CtxMasterQ(entry data.MasterQ) func(context.Context) context.Context {
 	return func(ctx context.Context) context.Context {
-		return context.WithValue(ctx, BalancesQCtxKey, entry)
+		return context.WithValue(ctx, masterQCtxKey, entry)
 	}
 }
 
+func MasterQ(r *http.Request) data.MasterQ {
+	return r.Context().Value(masterQCtxKey).(data.MasterQ).New()
+}
+
 func BalancesQ(r *http.Request) data.BalancesQ {
-	return r.Context().Value(BalancesQCtxKey).(data.BalancesQ).New()
+	return MasterQ(r).Balances()
+}
+
+func CtxBalanceCache(entry cache.Balances) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, balanceCacheCtxKey, entry)
+	}
+}
+
+func BalanceCache(r *http.Request) cache.Balances {
+	return r.Context().Value(balanceCacheCtxKey).(cache.Balances)
+}
+
+func CtxQuotas(entry types.Quotas) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, quotasCtxKey, entry)
+	}
+}
+
+func Quotas(r *http.Request) types.Quotas {
+	return r.Context().Value(quotasCtxKey).(types.Quotas)
+}
+
+func CtxAdmins(entry config.Admins) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, adminsCtxKey, entry)
+	}
+}
+
+func Admins(r *http.Request) config.Admins {
+	return r.Context().Value(adminsCtxKey).(config.Admins)
+}
+
+func CtxUser(entry *resources.User) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, userCtxKey, entry)
+	}
+}
+
+// User returns user authenticated by CheckAuthorization middleware, nil for anonymous requests
+func User(r *http.Request) *resources.User {
+	user, _ := r.Context().Value(userCtxKey).(*resources.User)
+	return user
+}
+
+func CtxAnonymous(entry config.AnonymousConfig) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, anonymousCtxKey, entry)
+	}
+}
+
+func Anonymous(r *http.Request) config.AnonymousConfig {
+	return r.Context().Value(anonymousCtxKey).(config.AnonymousConfig)
+}
+
+func CtxChallenge(entry *pow.Challenge) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, challengeCtxKey, entry)
+	}
+}
+
+// Challenge returns proof of work challenge of anonymous request, nil if request is not authorized by it
+func Challenge(r *http.Request) *pow.Challenge {
+	challenge, _ := r.Context().Value(challengeCtxKey).(*pow.Challenge)
+	return challenge
+}
+
+func CtxAddressPolicy(entry types.AddressPolicy) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, addressPolicyCtxKey, entry)
+	}
+}
+
+func AddressPolicy(r *http.Request) types.AddressPolicy {
+	return r.Context().Value(addressPolicyCtxKey).(types.AddressPolicy)
+}
+
+func CtxIPLimits(entry config.IPLimits) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, ipLimitsCtxKey, entry)
+	}
+}
+
+func IPLimits(r *http.Request) config.IPLimits {
+	return r.Context().Value(ipLimitsCtxKey).(config.IPLimits)
+}
+
+func CtxRequestLimiter(entry *ratelimit.IPLimiter) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, requestLimiterCtxKey, entry)
+	}
+}
+
+func RequestLimiter(r *http.Request) *ratelimit.IPLimiter {
+	return r.Context().Value(requestLimiterCtxKey).(*ratelimit.IPLimiter)
+}
+
+func CtxClientIP(entry string) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, clientIPCtxKey, entry)
+	}
+}
+
+func CtxOwnership(entry *ownership.Issuer) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, ownershipCtxKey, entry)
+	}
+}
+
+// Ownership returns nil if receivers are not required to prove address ownership
+func Ownership(r *http.Request) *ownership.Issuer {
+	return r.Context().Value(ownershipCtxKey).(*ownership.Issuer)
+}
+
+func CtxRecipientBalances(entry types.RecipientBalances) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, recipientBalancesCtxKey, entry)
+	}
+}
+
+func RecipientBalances(r *http.Request) types.RecipientBalances {
+	return r.Context().Value(recipientBalancesCtxKey).(types.RecipientBalances)
+}
+
+func CtxBatch(entry config.BatchConfig) func(context.Context) context.Context {
+	return func(ctx context.Context) context.Context {
+		return context.WithValue(ctx, batchCtxKey, entry)
+	}
+}
+
+func Batch(r *http.Request) config.BatchConfig {
+	return r.Context().Value(batchCtxKey).(config.BatchConfig)
 }
@@ -0,0 +1,23 @@
+package helpers
+
+import (
+	"net"
+	"net/http"
+)
+
+// PeerIP returns ip of the peer connected to the service, which may be a proxy
+func PeerIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// RemoteIP returns client ip resolved by RealIP middleware, or peer ip if it was not resolved
+func RemoteIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPCtxKey).(string); ok {
+		return ip
+	}
+	return PeerIP(r)
+}
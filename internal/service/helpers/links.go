@@ -0,0 +1,26 @@
+package helpers
+
+import (
+	"faucet-svc/resources"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+// GetOffsetLinks builds JSON:API pagination links for offset page params
+func GetOffsetLinks(r *http.Request, params pgdb.OffsetPageParams) *resources.Links {
+	return &resources.Links{
+		Self: pageLink(r.URL, params, params.PageNumber),
+		Next: pageLink(r.URL, params, params.PageNumber+1),
+	}
+}
+
+func pageLink(u *url.URL, params pgdb.OffsetPageParams, number uint64) string {
+	query := u.Query()
+	query.Set("page[limit]", fmt.Sprint(params.Limit))
+	query.Set("page[number]", fmt.Sprint(number))
+	query.Set("page[order]", params.Order)
+	return fmt.Sprintf("%s?%s", u.Path, query.Encode())
+}
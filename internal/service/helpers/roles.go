@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"faucet-svc/internal/types"
+	"faucet-svc/resources"
+	"net/http"
+)
+
+// UserRole returns effective role of authenticated user, ids listed in admin config are always admins
+func UserRole(r *http.Request) string {
+	user := User(r)
+	if user == nil {
+		return types.RoleUser
+	}
+	if Admins(r).Contains(user.Id) {
+		return types.RoleAdmin
+	}
+
+	var roles []string
+	if user.Attributes != nil {
+		roles = user.Attributes.Roles
+	}
+	for _, role := range roles {
+		// anonymous role restricts, so it wins over any other
+		if role == types.RoleAnonymous {
+			return types.RoleAnonymous
+		}
+	}
+	return types.EffectiveRole(roles)
+}
+
+// NewAnonymousUser identifies captcha verified request without account by its ip,
+// so per user quotas of anonymous role become per ip ones
+func NewAnonymousUser(ip string) *resources.User {
+	return &resources.User{
+		Id:   "anonymous:" + ip,
+		Type: "user",
+		Attributes: &resources.UserAttributes{
+			Roles: []string{types.RoleAnonymous},
+		},
+	}
+}
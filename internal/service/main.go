@@ -1,12 +1,21 @@
 package service
 
 import (
-	"faucet-svc/doorman"
+	"context"
+	"faucet-svc/internal/auth"
+	"faucet-svc/internal/data/pg"
+	"faucet-svc/internal/ownership"
+	"faucet-svc/internal/service/cache"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/service/payouts"
+	"faucet-svc/internal/service/ratelimit"
 	types2 "faucet-svc/internal/types"
 	"faucet-svc/internal/types/chains"
 	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/running"
 	"net"
 	"net/http"
+	"time"
 
 	"faucet-svc/internal/config"
 	"gitlab.com/distributed_lab/kit/copus/types"
@@ -15,20 +24,36 @@ import (
 )
 
 type service struct {
-	log      *logan.Entry
-	copus    types.Copus
-	listener net.Listener
-	chains   chains.Chains
-	signers  config.Signers
-	tokens   types2.EvmTokens
-	doorman  doorman.Connector
-	db       *pgdb.DB
+	log               *logan.Entry
+	copus             types.Copus
+	listener          net.Listener
+	chains            chains.Chains
+	signers           config.Signers
+	tokens            types2.EvmTokens
+	auth              auth.Authenticator
+	db                *pgdb.DB
+	balances          cache.Balances
+	quotas            types2.Quotas
+	admins            config.Admins
+	anonymous         config.AnonymousConfig
+	addressPolicy     types2.AddressPolicy
+	ipLimits          config.IPLimits
+	requests          *ratelimit.IPLimiter
+	ownership         *ownership.Issuer
+	recipientBalances types2.RecipientBalances
+	batch             config.BatchConfig
 }
 
 func (s *service) run() error {
 	s.log.Info("Service started")
 	r := s.router()
 
+	go s.balances.Run(context.Background())
+	go payouts.NewReconciler(s.log, pg.NewMasterQ(s.db), s.chains).Run(context.Background())
+	if s.anonymous.Pow != nil {
+		go s.cleanChallenges(context.Background())
+	}
+
 	if err := s.copus.RegisterChi(r); err != nil {
 		return errors.Wrap(err, "cop failed")
 	}
@@ -36,17 +61,45 @@ func (s *service) run() error {
 	return http.Serve(s.listener, r)
 }
 
+// cleanChallenges drops used challenges which are expired, so they can't be replayed anyway
+func (s *service) cleanChallenges(ctx context.Context) {
+	challenges := pg.NewMasterQ(s.db).Challenges()
+	running.WithBackOff(ctx, s.log, "challenges-cleaner", func(ctx context.Context) error {
+		return challenges.DeleteExpired(time.Now().UTC())
+	}, time.Hour, time.Minute, 10*time.Minute)
+}
+
 func newService(cfg config.Config) *service {
 	signers := cfg.Signers()
+	chainList := cfg.Chains(signers)
+	tokens := cfg.EvmTokens()
+	signerAddress := func(kind string) string {
+		return helpers.GetSignerAddress(kind, signers)
+	}
+
+	if cfg.AuthConfig().Mode == config.AuthModeDev {
+		cfg.Log().Warn("dev authentication is enabled, it must never be used in production")
+	}
+
 	return &service{
-		log:      cfg.Log(),
-		copus:    cfg.Copus(),
-		listener: cfg.Listener(),
-		chains:   cfg.Chains(signers),
-		signers:  signers,
-		tokens:   cfg.EvmTokens(),
-		doorman:  cfg.DoormanConnector(),
-		db:       cfg.DB(),
+		log:               cfg.Log(),
+		copus:             cfg.Copus(),
+		listener:          cfg.Listener(),
+		chains:            chainList,
+		signers:           signers,
+		tokens:            tokens,
+		auth:              cfg.Authenticator(),
+		db:                cfg.DB(),
+		balances:          cache.NewBalances(cfg.Log(), cfg.BalanceCache(), chainList, tokens, signerAddress),
+		quotas:            cfg.Quotas(),
+		admins:            cfg.Admins(),
+		anonymous:         cfg.Anonymous(),
+		addressPolicy:     cfg.AddressPolicy(),
+		ipLimits:          cfg.IPLimits(),
+		requests:          ratelimit.NewIPLimiter(cfg.IPLimits().Requests),
+		ownership:         cfg.Ownership(),
+		recipientBalances: cfg.RecipientBalances(),
+		batch:             cfg.Batch(),
 	}
 }
 
@@ -0,0 +1,20 @@
+package middlewares
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"net/http"
+)
+
+// CheckAdmin must be used after CheckAuthorization, it relies on authenticated user
+func CheckAdmin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if helpers.User(r) == nil || helpers.UserRole(r) != types.RoleAdmin {
+			ape.RenderErr(w, problems.Forbidden())
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,62 @@
+package middlewares
+
+import (
+	"faucet-svc/internal/captcha"
+	"faucet-svc/internal/service/helpers"
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"net/http"
+)
+
+// CheckAuthorizationOrAnonymous lets requests without Authorization header in as anonymous users
+// once they pass captcha or carry proof of work challenge, if anonymous mode is disabled it is the same as CheckAuthorization.
+// Solution of the challenge is bound to request body, so it is verified by handler.
+func CheckAuthorizationOrAnonymous(next http.Handler) http.Handler {
+	authorized := CheckAuthorization(next)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		anonymous := helpers.Anonymous(r)
+		if !anonymous.Enabled || r.Header.Get("Authorization") != "" {
+			authorized.ServeHTTP(w, r)
+			return
+		}
+
+		ip := helpers.RemoteIP(r)
+		ctx := helpers.CtxUser(helpers.NewAnonymousUser(ip))(r.Context())
+
+		if token := r.Header.Get("Pow-Challenge"); token != "" && anonymous.Pow != nil {
+			challenge, err := anonymous.Pow.Issuer.Parse(token)
+			if err != nil {
+				problem := problems.Forbidden()
+				problem.Detail = "Proof of work challenge is invalid or expired"
+				ape.RenderErr(w, problem)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(helpers.CtxChallenge(&challenge)(ctx)))
+			return
+		}
+
+		token := r.Header.Get("Captcha-Token")
+		if token == "" || anonymous.Captcha == nil {
+			problem := problems.Unauthorized()
+			problem.Detail = "Authorization, Captcha-Token or Pow-Challenge header is required"
+			ape.RenderErr(w, problem)
+			return
+		}
+
+		if err := anonymous.Captcha.Verify(r.Context(), token, ip); err != nil {
+			if errors.Cause(err) == captcha.ErrInvalid {
+				helpers.Log(r).WithError(err).Debug("captcha is rejected")
+				problem := problems.Forbidden()
+				problem.Detail = "Captcha verification failed"
+				ape.RenderErr(w, problem)
+				return
+			}
+			helpers.Log(r).WithError(err).Error("failed to verify captcha")
+			ape.RenderErr(w, problems.InternalError())
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
@@ -1,25 +1,47 @@
 package middlewares
 
 import (
+	"faucet-svc/internal/auth"
 	"faucet-svc/internal/service/helpers"
 	"faucet-svc/resources"
 	"gitlab.com/distributed_lab/ape"
 	"gitlab.com/distributed_lab/ape/problems"
+	"gitlab.com/distributed_lab/logan/v3/errors"
 	"net/http"
 )
 
 func ValidateJwt(r *http.Request) (user *resources.User, err error) {
-	doorman := helpers.DoormanConnector(r)
-	user, err = doorman.Authenticate(r)
+	user, err = helpers.Authenticator(r).Authenticate(r)
 	return
 }
 
 func CheckAuthorization(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if _, err := ValidateJwt(r); err != nil {
+		user, err := ValidateJwt(r)
+		if err != nil {
+			if errors.Cause(err) == auth.ErrUnauthorized {
+				helpers.Log(r).WithError(err).Debug("request is unauthorized")
+				ape.RenderErr(w, problems.Unauthorized())
+				return
+			}
+			helpers.Log(r).WithError(err).Error("failed to authenticate request")
+			ape.RenderErr(w, problems.InternalError())
+			return
+		}
+		if user == nil || user.Id == "" {
+			helpers.Log(r).Debug("authenticated user has no id")
 			ape.RenderErr(w, problems.Unauthorized())
 			return
 		}
+
+		next.ServeHTTP(w, r.WithContext(helpers.CtxUser(user)(r.Context())))
+	})
+}
+
+// StripIdentityHeaders drops identity headers set by clients, authenticated user is available only via context
+func StripIdentityHeaders(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.Header.Del("User-Id")
 		next.ServeHTTP(w, r)
 	})
 }
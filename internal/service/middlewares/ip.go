@@ -0,0 +1,87 @@
+package middlewares
+
+import (
+	"faucet-svc/internal/service/helpers"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/distributed_lab/ape"
+	"gitlab.com/distributed_lab/ape/problems"
+)
+
+// RealIP resolves client ip from X-Forwarded-For or X-Real-IP headers, they are honored only if
+// the peer is a trusted proxy, otherwise anyone could spoof the ip to bypass per ip limits
+func RealIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits := helpers.IPLimits(r)
+		ip := helpers.PeerIP(r)
+
+		peer := net.ParseIP(ip)
+		if peer != nil && limits.IsTrusted(peer) {
+			if forwarded := forwardedIP(r, limits.IsTrusted); forwarded != nil {
+				ip = forwarded.String()
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(helpers.CtxClientIP(ip)(r.Context())))
+	})
+}
+
+// forwardedIP walks X-Forwarded-For from the nearest hop and returns the first address not belonging to trusted proxy,
+// as hops to the left of it could be set by the client
+func forwardedIP(r *http.Request, isTrusted func(ip net.IP) bool) net.IP {
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+
+	var client net.IP
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := net.ParseIP(strings.TrimSpace(hops[i]))
+		if hop == nil {
+			break
+		}
+		client = hop
+		if !isTrusted(hop) {
+			break
+		}
+	}
+	if client != nil {
+		return client
+	}
+
+	return net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP")))
+}
+
+// LimitRequests renders 429 if client ip or its subnet exceeded request rate limit
+func LimitRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := net.ParseIP(helpers.RemoteIP(r))
+		if ip == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		limits := helpers.IPLimits(r)
+		now := time.Now().UTC()
+		scope, retryAt, ok := helpers.RequestLimiter(r).Allow(ip.String(), limits.Subnet(ip).String(), now)
+		if !ok {
+			retryAfter := int64(retryAt.Sub(now).Seconds()) + 1
+			w.Header().Set("Retry-After", strconv.FormatInt(retryAfter, 10))
+
+			problem := problems.TooManyRequests()
+			problem.Detail = "Too many requests from the " + scope
+			problem.Meta = &map[string]interface{}{
+				"scope":    scope,
+				"retry_at": retryAt,
+			}
+			ape.RenderErr(w, problem)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,67 @@
+package middlewares
+
+import (
+	"faucet-svc/internal/config"
+	"faucet-svc/internal/service/helpers"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	var trusted []*net.IPNet
+	for _, cidr := range []string{"10.0.0.0/8", "fd00::/8"} {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			t.Fatalf("invalid cidr %s: %v", cidr, err)
+		}
+		trusted = append(trusted, network)
+	}
+	limits := config.IPLimits{TrustedProxies: trusted}
+
+	cases := []struct {
+		name      string
+		peer      string
+		forwarded []string
+		realIP    string
+		want      string
+	}{
+		{"no headers", "203.0.113.7:1234", nil, "", "203.0.113.7"},
+		{"spoofed forwarded from untrusted peer", "203.0.113.7:1234", []string{"198.51.100.1"}, "", "203.0.113.7"},
+		{"spoofed real ip from untrusted peer", "203.0.113.7:1234", nil, "198.51.100.1", "203.0.113.7"},
+		{"forwarded from trusted proxy", "10.0.0.1:1234", []string{"198.51.100.1"}, "", "198.51.100.1"},
+		{"real ip from trusted proxy", "10.0.0.1:1234", nil, "198.51.100.1", "198.51.100.1"},
+		{"spoofed hop left of client", "10.0.0.1:1234", []string{"192.0.2.1, 198.51.100.1"}, "", "198.51.100.1"},
+		{"chain of trusted proxies", "10.0.0.1:1234", []string{"198.51.100.1, 10.0.0.3", "10.0.0.2"}, "", "198.51.100.1"},
+		{"only trusted hops", "10.0.0.1:1234", []string{"10.0.0.3, 10.0.0.2"}, "", "10.0.0.3"},
+		{"malformed hop", "10.0.0.1:1234", []string{"198.51.100.1, garbage"}, "", "10.0.0.1"},
+		{"forwarded preferred to real ip", "10.0.0.1:1234", []string{"198.51.100.1"}, "192.0.2.1", "198.51.100.1"},
+		{"malformed real ip", "10.0.0.1:1234", nil, "garbage", "10.0.0.1"},
+		{"ipv6 trusted proxy", "[fd00::1]:1234", []string{"2001:db8::1"}, "", "2001:db8::1"},
+		{"ipv6 untrusted peer", "[2001:db8::2]:1234", []string{"2001:db8::1"}, "", "2001:db8::2"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tc.peer
+			for _, forwarded := range tc.forwarded {
+				r.Header.Add("X-Forwarded-For", forwarded)
+			}
+			if tc.realIP != "" {
+				r.Header.Set("X-Real-IP", tc.realIP)
+			}
+			r = r.WithContext(helpers.CtxIPLimits(limits)(r.Context()))
+
+			var got string
+			RealIP(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = helpers.RemoteIP(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tc.want {
+				t.Errorf("RealIP() = %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,164 @@
+package payouts
+
+import (
+	"context"
+	"faucet-svc/internal/data"
+	"faucet-svc/internal/pow"
+	"faucet-svc/internal/types/chains"
+	"faucet-svc/internal/types/pg"
+	"time"
+
+	"gitlab.com/distributed_lab/logan/v3"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+	"gitlab.com/distributed_lab/running"
+)
+
+// reconcileAfter is the age after which pending payout is considered abandoned by crashed request,
+// payouts of chains.AsyncBroadcaster chains are reconciled right away
+const reconcileAfter = 5 * time.Minute
+
+// Reserve records pending payouts and counts their amounts to user balances in one db transaction,
+// it must be called before the transaction paying them is broadcast. Payouts share the user balance and are a single claim,
+// which must fit into max amount and cooldown of the quota, data.ErrQuotaExceeded or data.ErrClaimCooldown is returned otherwise.
+// The balance is checked under row lock, so concurrent requests can't exceed the quota together.
+func Reserve(q data.MasterQ, claim data.Claim, reserved ...*pg.Payout) error {
+	return q.Transaction(func(q data.MasterQ) error {
+		return reserve(q, claim, reserved...)
+	})
+}
+
+// ReserveWithChallenge is Reserve for payouts authorized by proof of work challenge, the challenge is marked as used
+// in the same db transaction, so it is spent only by the payout which is actually reserved.
+// data.ErrChallengeUsed is returned if the challenge was already used.
+func ReserveWithChallenge(q data.MasterQ, challenge pow.Challenge, claim data.Claim, reserved ...*pg.Payout) error {
+	return q.Transaction(func(q data.MasterQ) error {
+		if err := q.Challenges().Insert(challenge.ID, challenge.ExpiresAt); err != nil {
+			return errors.Wrap(err, "failed to save used challenge")
+		}
+		return reserve(q, claim, reserved...)
+	})
+}
+
+func reserve(q data.MasterQ, claim data.Claim, reserved ...*pg.Payout) error {
+	for _, payout := range reserved {
+		if err := q.Payouts().Insert(payout); err != nil {
+			return errors.Wrap(err, "failed to insert payout")
+		}
+
+		balance := payout.Balance()
+		if err := q.Balances().Update(&balance, claim); err != nil {
+			return errors.Wrap(err, "failed to update balance")
+		}
+	}
+	return nil
+}
+
+// Confirm marks payout as sent after successful broadcast
+func Confirm(q data.MasterQ, payout pg.Payout) error {
+	return errors.Wrap(
+		q.Payouts().UpdateStatus(payout.ID, pg.PayoutStatusSent),
+		"failed to update payout status",
+	)
+}
+
+// UpdateTxHash records hash transaction of the payout got from the network on broadcast
+func UpdateTxHash(q data.MasterQ, payout pg.Payout, txHash string) error {
+	return errors.Wrap(
+		q.Payouts().UpdateTxHash(payout.ID, txHash),
+		"failed to update payout transaction hash",
+	)
+}
+
+// Release marks payout as failed and returns reserved amount to user quota
+func Release(q data.MasterQ, payout pg.Payout) error {
+	return q.Transaction(func(q data.MasterQ) error {
+		if err := q.Payouts().UpdateStatus(payout.ID, pg.PayoutStatusFailed); err != nil {
+			return errors.Wrap(err, "failed to update payout status")
+		}
+
+		balance := payout.Balance()
+		if err := q.Balances().Subtract(&balance); err != nil {
+			return errors.Wrap(err, "failed to subtract balance")
+		}
+		return nil
+	})
+}
+
+type Reconciler struct {
+	log    *logan.Entry
+	q      data.MasterQ
+	chains chains.Chains
+}
+
+func NewReconciler(log *logan.Entry, q data.MasterQ, chains chains.Chains) *Reconciler {
+	return &Reconciler{
+		log:    log.WithField("service", "payouts-reconciler"),
+		q:      q,
+		chains: chains,
+	}
+}
+
+// Run resolves payouts left pending by crashes and confirms payouts broadcast asynchronously,
+// the first pass is done right on startup
+func (r *Reconciler) Run(ctx context.Context) {
+	running.WithBackOff(ctx, r.log, "payouts-reconciler", r.Reconcile, time.Minute, time.Minute, 10*time.Minute)
+}
+
+func (r *Reconciler) Reconcile(_ context.Context) error {
+	pending, err := r.q.New().Payouts().
+		FilterByStatus(pg.PayoutStatusPending).
+		Select()
+	if err != nil {
+		return errors.Wrap(err, "failed to select pending payouts")
+	}
+
+	for _, payout := range pending {
+		// failing rpc of one chain must not hold payouts of others, the payout is retried on the next pass
+		if err := r.reconcile(payout); err != nil {
+			r.log.WithError(err).WithFields(logan.F{
+				"payout_id": payout.ID,
+				"tx_hash":   payout.TxHash,
+			}).Error("failed to reconcile payout")
+		}
+	}
+	return nil
+}
+
+func (r *Reconciler) reconcile(payout pg.Payout) error {
+	chain, ok := r.chains.Get(payout.ChainId, payout.ChainType)
+	if !ok {
+		r.log.WithField("payout_id", payout.ID).Warn("chain of pending payout is not configured anymore")
+		return nil
+	}
+
+	age := time.Now().UTC().Sub(payout.CreatedAt.UTC())
+	async, isAsync := chain.(chains.AsyncBroadcaster)
+	if !isAsync && age < reconcileAfter {
+		// request paying it may be still in progress
+		return nil
+	}
+
+	status, err := chain.TxStatus(payout.TxHash)
+	if err != nil {
+		return errors.Wrap(err, "failed to get transaction status")
+	}
+
+	switch status {
+	case chains.TxStatusSuccess:
+		return Confirm(r.q.New(), payout)
+	case chains.TxStatusNotFound:
+		if isAsync && age < async.DropTimeout() {
+			// transaction may be not propagated yet
+			return nil
+		}
+		fallthrough
+	case chains.TxStatusFailed:
+		r.log.WithFields(logan.F{
+			"payout_id": payout.ID,
+			"tx_status": status,
+		}).Info("releasing undelivered payout")
+		return Release(r.q.New(), payout)
+	default:
+		return nil
+	}
+}
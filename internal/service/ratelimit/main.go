@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"faucet-svc/internal/config"
+	"sync"
+	"time"
+)
+
+type window struct {
+	startedAt time.Time
+	hits      uint64
+}
+
+// Limiter counts hits of keys within fixed windows in memory, nil Limiter allows everything
+type Limiter struct {
+	limit config.RateLimit
+
+	mu      sync.Mutex
+	windows map[string]*window
+	sweptAt time.Time
+}
+
+// New returns nil if the limit is disabled
+func New(limit config.RateLimit) *Limiter {
+	if limit.Limit == 0 {
+		return nil
+	}
+	return &Limiter{
+		limit:   limit,
+		windows: make(map[string]*window),
+	}
+}
+
+// Allow registers hit of the key, if the limit is exceeded it returns false and the moment the key is allowed again
+func (l *Limiter) Allow(key string, now time.Time) (time.Time, bool) {
+	if l == nil {
+		return time.Time{}, true
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(now)
+	current, ok := l.windows[key]
+	if !ok || !now.Before(current.startedAt.Add(l.limit.Window)) {
+		current = &window{startedAt: now}
+		l.windows[key] = current
+	}
+
+	if current.hits >= l.limit.Limit {
+		return current.startedAt.Add(l.limit.Window), false
+	}
+	current.hits++
+	return time.Time{}, true
+}
+
+// sweep drops finished windows once per window, so memory is not held by keys seen long ago
+func (l *Limiter) sweep(now time.Time) {
+	if now.Before(l.sweptAt.Add(l.limit.Window)) {
+		return
+	}
+	for key, w := range l.windows {
+		if !now.Before(w.startedAt.Add(l.limit.Window)) {
+			delete(l.windows, key)
+		}
+	}
+	l.sweptAt = now
+}
+
+// IPLimiter limits requests of a single ip and of the whole subnet it belongs to
+type IPLimiter struct {
+	ip     *Limiter
+	subnet *Limiter
+}
+
+func NewIPLimiter(limits config.IPRateLimits) *IPLimiter {
+	return &IPLimiter{
+		ip:     New(limits.IP),
+		subnet: New(limits.Subnet),
+	}
+}
+
+// Allow returns scope of exceeded limit, ip or subnet, and the moment requests are allowed again
+func (l *IPLimiter) Allow(ip, subnet string, now time.Time) (string, time.Time, bool) {
+	if retryAt, ok := l.ip.Allow(ip, now); !ok {
+		return "ip", retryAt, false
+	}
+	if retryAt, ok := l.subnet.Allow(subnet, now); !ok {
+		return "subnet", retryAt, false
+	}
+	return "", time.Time{}, true
+}
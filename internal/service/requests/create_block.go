@@ -0,0 +1,42 @@
+package requests
+
+import (
+	"encoding/json"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type CreateBlockRequest struct {
+	Data resources.Block
+}
+
+func NewCreateBlockRequest(r *http.Request) (CreateBlockRequest, error) {
+	var request CreateBlockRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return request, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return request, request.validate()
+}
+
+func (r *CreateBlockRequest) validate() error {
+	return validation.Errors{
+		"/data/type": validation.Validate(r.Data.Type, validation.Required, validation.In(resources.BLOCK)),
+		"/data/attributes/kind": validation.Validate(
+			r.Data.Attributes.Kind, validation.Required,
+			validation.In(string(pg.BlockKindUser), string(pg.BlockKindAddress)),
+		),
+		"/data/attributes/chain_type": validation.Validate(r.Data.Attributes.ChainType,
+			validation.When(r.Data.Attributes.Kind == string(pg.BlockKindAddress), validation.Required),
+			validation.When(r.Data.Attributes.Kind != string(pg.BlockKindAddress), validation.Nil),
+			validation.In("evm", "solana", "near"),
+		),
+		"/data/attributes/value":  validation.Validate(r.Data.Attributes.Value, validation.Required, validation.Length(1, 255)),
+		"/data/attributes/reason": validation.Validate(r.Data.Attributes.Reason, validation.Length(0, 1024)),
+	}.Filter()
+}
@@ -0,0 +1,23 @@
+package requests
+
+import (
+	"net/http"
+
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+type GetBalanceListRequest struct {
+	pgdb.OffsetPageParams
+}
+
+func NewGetBalanceListRequest(r *http.Request) (GetBalanceListRequest, error) {
+	var request GetBalanceListRequest
+
+	params, err := newOffsetPageParams(r.URL.Query())
+	if err != nil {
+		return request, err
+	}
+	request.OffsetPageParams = params
+
+	return request, nil
+}
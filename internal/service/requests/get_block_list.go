@@ -0,0 +1,36 @@
+package requests
+
+import (
+	"faucet-svc/internal/types/pg"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+type GetBlockListRequest struct {
+	pgdb.OffsetPageParams
+	FilterKind *string
+}
+
+func NewGetBlockListRequest(r *http.Request) (GetBlockListRequest, error) {
+	var request GetBlockListRequest
+
+	query := r.URL.Query()
+	params, err := newOffsetPageParams(query)
+	if err != nil {
+		return request, err
+	}
+	request.OffsetPageParams = params
+
+	if kind := query.Get("filter[kind]"); kind != "" {
+		request.FilterKind = &kind
+	}
+
+	return request, validation.Errors{
+		"filter[kind]": validation.Validate(request.FilterKind, validation.In(
+			string(pg.BlockKindUser),
+			string(pg.BlockKindAddress),
+		)),
+	}.Filter()
+}
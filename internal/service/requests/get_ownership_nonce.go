@@ -0,0 +1,34 @@
+package requests
+
+import (
+	"faucet-svc/internal/types/chains"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+type GetOwnershipNonceRequest struct {
+	ChainType string
+	Address   string
+}
+
+func NewGetOwnershipNonceRequest(r *http.Request) (GetOwnershipNonceRequest, error) {
+	request := GetOwnershipNonceRequest{
+		ChainType: chi.URLParam(r, "type"),
+		Address:   chi.URLParam(r, "address"),
+	}
+
+	return request, request.validate()
+}
+
+func (r *GetOwnershipNonceRequest) validate() error {
+	return validation.Errors{
+		"type": validation.Validate(r.ChainType, validation.Required, validation.In("evm", "solana", "near")),
+		"address": validation.Validate(r.Address, validation.Required,
+			validation.When(r.ChainType == "evm", validation.By(chains.ValidateEvmAddress)),
+			validation.When(r.ChainType == "near", validation.By(chains.ValidateNearAddress)),
+			validation.When(r.ChainType == "solana", validation.By(chains.ValidateSolanaAddress)),
+		),
+	}.Filter()
+}
@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"faucet-svc/internal/types/pg"
+	"net"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/kit/pgdb"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type GetPayoutListRequest struct {
+	pgdb.OffsetPageParams
+	FilterUserID    *string
+	FilterStatus    *string
+	FilterReceiver  *string
+	FilterChainType *string
+	FilterChainID   *string
+	// FilterIP is an ip or cidr of the network payouts were requested from
+	FilterIP *string
+}
+
+func NewGetPayoutListRequest(r *http.Request) (GetPayoutListRequest, error) {
+	var request GetPayoutListRequest
+
+	query := r.URL.Query()
+	params, err := newOffsetPageParams(query)
+	if err != nil {
+		return request, err
+	}
+	request.OffsetPageParams = params
+
+	filter := func(name string) *string {
+		if value := query.Get(name); value != "" {
+			return &value
+		}
+		return nil
+	}
+	request.FilterUserID = filter("filter[user_id]")
+	request.FilterStatus = filter("filter[status]")
+	request.FilterReceiver = filter("filter[receiver]")
+	request.FilterChainType = filter("filter[chain_type]")
+	request.FilterChainID = filter("filter[chain_id]")
+	request.FilterIP = filter("filter[ip]")
+
+	return request, request.validate()
+}
+
+func (r *GetPayoutListRequest) validate() error {
+	return validation.Errors{
+		"filter[status]": validation.Validate(r.FilterStatus, validation.In(
+			string(pg.PayoutStatusPending),
+			string(pg.PayoutStatusSent),
+			string(pg.PayoutStatusFailed),
+		)),
+		"filter[chain_id]": validation.Validate(r.FilterChainID,
+			validation.When(r.FilterChainType == nil, validation.Nil.Error("requires filter[chain_type]")),
+		),
+		"filter[ip]": validation.Validate(r.FilterIP, validation.When(r.FilterIP != nil, validation.By(validateNetwork))),
+	}.Filter()
+}
+
+func validateNetwork(value interface{}) error {
+	network := *value.(*string)
+	if net.ParseIP(network) != nil {
+		return nil
+	}
+	if _, _, err := net.ParseCIDR(network); err != nil {
+		return errors.New("must be an ip or cidr")
+	}
+	return nil
+}
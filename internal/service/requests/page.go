@@ -0,0 +1,40 @@
+package requests
+
+import (
+	"net/url"
+	"strconv"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/kit/pgdb"
+)
+
+// newOffsetPageParams parses page[limit], page[number] and page[order], newest entries go first by default
+func newOffsetPageParams(query url.Values) (pgdb.OffsetPageParams, error) {
+	params := pgdb.OffsetPageParams{
+		Limit: 15,
+		Order: pgdb.OrderTypeDesc,
+	}
+	if order := query.Get("page[order]"); order != "" {
+		params.Order = order
+	}
+
+	errs := validation.Errors{}
+	if raw := query.Get("page[limit]"); raw != "" {
+		limit, err := strconv.ParseUint(raw, 10, 64)
+		errs["page[limit]"] = err
+		params.Limit = limit
+	}
+	if raw := query.Get("page[number]"); raw != "" {
+		number, err := strconv.ParseUint(raw, 10, 64)
+		errs["page[number]"] = err
+		params.PageNumber = number
+	}
+	if err := errs.Filter(); err != nil {
+		return params, err
+	}
+
+	return params, validation.Errors{
+		"page[limit]": validation.Validate(params.Limit, validation.Min(uint64(1)), validation.Max(uint64(100))),
+		"page[order]": validation.Validate(params.Order, validation.In(pgdb.OrderTypeAsc, pgdb.OrderTypeDesc)),
+	}.Filter()
+}
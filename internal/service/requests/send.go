@@ -1,8 +1,11 @@
 package requests
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types"
 	"faucet-svc/internal/types/chains"
 	"faucet-svc/resources"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
@@ -14,6 +17,12 @@ import (
 
 type CreateSendRequest struct {
 	Data resources.Send
+	// Amount is resolved amount in base units, taken either from amount or human_amount
+	Amount *big.Int `json:"-"`
+	// Token is resolved configured token of token_address, nil for native currency
+	Token types.EvmToken `json:"-"`
+	// IdempotencyKey is taken from Idempotency-Key header, nil if absent
+	IdempotencyKey *string `json:"-"`
 }
 
 func NewCreateSendRequest(r *http.Request) (CreateSendRequest, error) {
@@ -23,11 +32,20 @@ func NewCreateSendRequest(r *http.Request) (CreateSendRequest, error) {
 		return request, errors.Wrap(err, "failed to unmarshal")
 	}
 
+	if key, ok := r.Header["Idempotency-Key"]; ok && len(key) != 0 {
+		request.IdempotencyKey = &key[0]
+	}
+
 	return request, request.validate(r)
 }
 
 func (r *CreateSendRequest) validate(req *http.Request) error {
+	attributes := r.Data.Attributes
 	return validation.Errors{
+		"Idempotency-Key": validation.Validate(
+			r.IdempotencyKey,
+			validation.When(r.IdempotencyKey != nil, validation.Required, validation.Length(1, 255)),
+		),
 		"/data/":     validation.Validate(r.Data, validation.Required),
 		"/data/id":   validation.Validate(r.Data.ID, validation.Required),
 		"/data/type": validation.Validate(r.Data.Type, validation.Required),
@@ -47,24 +65,151 @@ func (r *CreateSendRequest) validate(req *http.Request) error {
 			),
 		),
 		"/data/attributes/amount": validation.Validate(
-			r.Data.Attributes.Amount, validation.Required,
-			validation.By(func(value interface{}) error {
-				amount := value.(big.Int)
-				if helpers.IsLessOrEq(&amount, big.NewInt(0)) {
-					return errors.New("must be greater than 0")
+			attributes.Amount,
+			validation.When(attributes.HumanAmount == nil, validation.Required),
+			validation.When(attributes.HumanAmount != nil, validation.Nil.Error("must not be set together with human_amount")),
+			validation.When(attributes.Amount != nil, validation.By(func(value interface{}) error {
+				if err := validatePositive(attributes.Amount); err != nil {
+					return err
 				}
+				r.Amount = attributes.Amount
 				return nil
-			}),
+			})),
+		),
+		"/data/attributes/human_amount": validation.Validate(
+			attributes.HumanAmount,
+			validation.When(attributes.HumanAmount != nil && attributes.Amount == nil, validation.By(func(value interface{}) error {
+				return r.resolveHumanAmount(req)
+			})),
+		),
+		"/data/attributes/ownership": validation.Validate(
+			attributes.Ownership,
+			validation.When(attributes.Ownership != nil, validation.By(func(value interface{}) error {
+				proof := attributes.Ownership
+				return validation.ValidateStruct(proof,
+					validation.Field(&proof.Nonce, validation.Required),
+					validation.Field(&proof.Signature, validation.Required),
+					validation.Field(&proof.PublicKey, validation.When(r.Data.Type == "near", validation.Required)),
+				)
+			})),
+		),
+		"/data/attributes/public_key": validation.Validate(
+			attributes.PublicKey,
+			validation.When(
+				attributes.PublicKey != nil,
+				validation.Required,
+				validation.By(func(value interface{}) error {
+					return r.validateNewAccount(req)
+				}),
+			),
 		),
 		"/data/attributes/token_address": validation.Validate(
-			&r.Data.Attributes.TokenAddress,
+			r.Data.Attributes.TokenAddress,
 			validation.When(
-				r.Data.Type == "evm" && r.Data.Attributes.TokenAddress != nil,
-				validation.NilOrNotEmpty,
+				r.Data.Attributes.TokenAddress != nil,
+				validation.Required,
 				validation.By(func(value interface{}) error {
-					return chains.ValidateEvmAddress(*r.Data.Attributes.TokenAddress)
+					if r.Data.Type == "evm" {
+						if err := chains.ValidateEvmAddress(*r.Data.Attributes.TokenAddress); err != nil {
+							return err
+						}
+					}
+					return r.resolveToken(req)
 				}),
 			),
 		),
 	}.Filter()
 }
+
+// resolveToken accepts only tokens configured for the requested chain,
+// so arbitrary contracts can't be called on faucet behalf
+func (r *CreateSendRequest) resolveToken(req *http.Request) error {
+	if _, ok := helpers.Chains(req).Get(r.Data.ID, string(r.Data.Type)); !ok {
+		// unknown chain is reported by handler as not found
+		return nil
+	}
+
+	token, ok := helpers.Tokens(req).Find(string(r.Data.Type), r.Data.ID, *r.Data.Attributes.TokenAddress)
+	if !ok {
+		if r.Data.Type != "evm" {
+			return errors.New("tokens are not configured for the chain")
+		}
+		return errors.New("token is not configured for the chain")
+	}
+
+	r.Token = token
+	return nil
+}
+
+// validateNewAccount checks that receiver account can be created by the requested chain
+func (r *CreateSendRequest) validateNewAccount(req *http.Request) error {
+	chain, ok := helpers.Chains(req).Get(r.Data.ID, string(r.Data.Type))
+	if !ok {
+		// unknown chain is reported by handler as not found
+		return nil
+	}
+
+	creator, ok := chain.(chains.AccountCreator)
+	if !ok {
+		return errors.New("account creation is not supported by the chain")
+	}
+	if r.Data.Attributes.TokenAddress != nil {
+		return errors.New("account can't be created with token payout")
+	}
+	return creator.ValidateNewAccount(r.Data.Attributes.To, *r.Data.Attributes.PublicKey)
+}
+
+// resolveHumanAmount converts human_amount into base units with decimals of requested chain or token
+func (r *CreateSendRequest) resolveHumanAmount(req *http.Request) error {
+	chain, ok := helpers.Chains(req).Get(r.Data.ID, string(r.Data.Type))
+	if !ok {
+		// unknown chain is reported by handler as not found
+		return nil
+	}
+
+	decimals, ok := helpers.GetDecimals(req, chain.Kind(), chain.ID(), r.Data.Attributes.TokenAddress)
+	if !ok {
+		return errors.New("decimals of the token are unknown")
+	}
+
+	amount, err := helpers.FromHumanBalance(*r.Data.Attributes.HumanAmount, decimals)
+	if err != nil {
+		return err
+	}
+	if err := validatePositive(amount); err != nil {
+		return err
+	}
+
+	r.Amount = amount
+	return nil
+}
+
+// Fingerprint identifies request body, so reuse of idempotency key with another body can be detected
+func (r *CreateSendRequest) Fingerprint() string {
+	tokenAddress := ""
+	if r.Data.Attributes.TokenAddress != nil {
+		tokenAddress = strings.ToLower(*r.Data.Attributes.TokenAddress)
+	}
+
+	fields := []string{
+		string(r.Data.Type),
+		r.Data.ID,
+		r.Data.Attributes.To,
+		r.Amount.String(),
+		tokenAddress,
+	}
+	if r.Data.Attributes.PublicKey != nil {
+		// appended only if set, so fingerprints of payouts made before account creation stay the same
+		fields = append(fields, *r.Data.Attributes.PublicKey)
+	}
+
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func validatePositive(amount *big.Int) error {
+	if helpers.IsLessOrEq(amount, big.NewInt(0)) {
+		return errors.New("must be greater than 0")
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package requests
+
+import (
+	"encoding/json"
+	"faucet-svc/internal/service/helpers"
+	"faucet-svc/internal/types/pg"
+	"faucet-svc/resources"
+	"fmt"
+	"net/http"
+	"strings"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"gitlab.com/distributed_lab/logan/v3/errors"
+)
+
+type CreateSendBatchRequest struct {
+	Data []resources.Send
+	// Items are validated sends in the order of data
+	Items []CreateSendRequest `json:"-"`
+}
+
+func NewCreateSendBatchRequest(r *http.Request) (CreateSendBatchRequest, error) {
+	var request CreateSendBatchRequest
+
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		return request, errors.Wrap(err, "failed to unmarshal")
+	}
+
+	return request, request.validate(r)
+}
+
+func (r *CreateSendBatchRequest) validate(req *http.Request) error {
+	errs := validation.Errors{
+		"/data": validation.Validate(r.Data, validation.Required, validation.Length(1, helpers.Batch(req).MaxItems)),
+	}.Filter()
+	if errs != nil {
+		return errs
+	}
+
+	result := validation.Errors{}
+	receivers := make(map[string]int)
+	r.Items = make([]CreateSendRequest, len(r.Data))
+	for i, send := range r.Data {
+		item := CreateSendRequest{Data: send}
+		prefix := fmt.Sprintf("/data/%d/", i)
+		if err := item.validate(req); err != nil {
+			for field, fieldErr := range err.(validation.Errors) {
+				result[strings.Replace(field, "/data/", prefix, 1)] = fieldErr
+			}
+			continue
+		}
+
+		// receiver can't be paid twice, as cooldowns and balance limits are checked before any payout
+		tokenAddress := ""
+		if send.Attributes.TokenAddress != nil {
+			tokenAddress = strings.ToLower(*send.Attributes.TokenAddress)
+		}
+		key := strings.Join([]string{
+			string(send.Type),
+			send.ID,
+			pg.NormalizeAddress(string(send.Type), send.Attributes.To),
+			tokenAddress,
+		}, ":")
+		if first, ok := receivers[key]; ok {
+			result[prefix+"attributes/to"] = errors.Errorf("duplicates receiver of item %d", first)
+			continue
+		}
+		receivers[key] = i
+
+		r.Items[i] = item
+	}
+	return result.Filter()
+}
@@ -8,11 +8,15 @@ type TransactionResponse struct {
 	Data resources.Transaction `json:"data"`
 }
 
-func NewTransactionResponse(id string) TransactionResponse {
+func NewTransactionResponse(id, status, amount string) TransactionResponse {
 	return TransactionResponse{
 		Data: resources.Transaction{
 			Id:   id,
 			Type: "transaction",
+			Attributes: &resources.TransactionAttributes{
+				Amount: amount,
+				Status: status,
+			},
 		},
 	}
 }
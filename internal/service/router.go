@@ -15,25 +15,52 @@ func (s *service) router() chi.Router {
 	r.Use(
 		ape.RecoverMiddleware(s.log),
 		ape.LoganMiddleware(s.log),
+		middlewares.StripIdentityHeaders,
 		ape.CtxMiddleware(
 			helpers.CtxLog(s.log),
 			helpers.CtxChains(s.chains),
 			helpers.CtxSigners(s.signers),
 			helpers.CtxTokens(s.tokens),
-			helpers.CtxDoormanConnector(s.doorman),
-			helpers.CtxBalancesQ(pg.NewBalancesQ(s.db)),
+			helpers.CtxAuthenticator(s.auth),
+			helpers.CtxMasterQ(pg.NewMasterQ(s.db)),
+			helpers.CtxBalanceCache(s.balances),
+			helpers.CtxQuotas(s.quotas),
+			helpers.CtxAdmins(s.admins),
+			helpers.CtxAnonymous(s.anonymous),
+			helpers.CtxAddressPolicy(s.addressPolicy),
+			helpers.CtxIPLimits(s.ipLimits),
+			helpers.CtxRequestLimiter(s.requests),
+			helpers.CtxOwnership(s.ownership),
+			helpers.CtxRecipientBalances(s.recipientBalances),
+			helpers.CtxBatch(s.batch),
 		),
+		middlewares.RealIP,
+		middlewares.LimitRequests,
 	)
 
 	r.Route("/faucet", func(r chi.Router) {
 		r.Get("/chains", handlers.GetChainList)
 		r.Get("/tokens", handlers.GetTokenList)
-		r.With(middlewares.CheckAuthorization).
+		r.Get("/challenge", handlers.GetChallenge)
+		r.Get("/addresses/{type}/{address}/nonce", handlers.GetOwnershipNonce)
+		r.With(middlewares.CheckAuthorizationOrAnonymous).
 			Post("/send", handlers.Send)
-	})
+		r.With(middlewares.CheckAuthorization).
+			Post("/send/batch", handlers.SendBatch)
+		r.With(middlewares.CheckAuthorization).
+			Get("/users/me/balances", handlers.GetMyBalanceList)
 
-	// TODO: delete
-	r.Get("/doorman/authenticate", handlers.Authenticate)
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(middlewares.CheckAuthorization, middlewares.CheckAdmin)
+			r.Get("/users/{id}/balances", handlers.GetUserBalanceList)
+			r.Get("/payouts", handlers.GetPayoutList)
+			r.Post("/payouts/{id}/refund", handlers.RefundPayout)
+			r.Get("/blocks", handlers.GetBlockList)
+			r.Post("/blocks", handlers.CreateBlock)
+			r.Delete("/blocks/{kind}/{value}", handlers.DeleteBlock)
+			r.Get("/wallets", handlers.GetWalletList)
+		})
+	})
 
 	return r
 }
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"strings"
+	"time"
+)
+
+// AddressPolicy restricts receiver addresses regardless of user sending to them.
+// Addresses are compared lowercased, the same way address blocks are stored.
+type AddressPolicy struct {
+	// Allow contains addresses exempt from deny lists, cooldown and funneling detection
+	Allow map[string]struct{}
+	// Deny maps denied address to the reason it is denied for
+	Deny map[string]string
+	// Cooldown is minimal period between two payouts to the same address on a chain, zero disables it
+	Cooldown  time.Duration
+	Funneling Funneling
+}
+
+// Funneling detects many users sending to the same receiver
+type Funneling struct {
+	// MaxUsers is the number of distinct users allowed to send to a receiver within Window, zero disables detection
+	MaxUsers uint64
+	Window   time.Duration
+	// Block adds receiver to the address blocks once funneling is detected
+	Block bool
+}
+
+func (p AddressPolicy) IsAllowed(address string) bool {
+	_, ok := p.Allow[strings.ToLower(address)]
+	return ok
+}
+
+// DenyReason returns the reason address is denied by config, if it is
+func (p AddressPolicy) DenyReason(address string) (string, bool) {
+	reason, ok := p.Deny[strings.ToLower(address)]
+	return reason, ok
+}
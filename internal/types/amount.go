@@ -1,7 +1,9 @@
 package types
 
 import (
+	"database/sql/driver"
 	"encoding/json"
+	"fmt"
 	"math/big"
 )
 
@@ -9,6 +11,12 @@ type Amount struct {
 	big.Int
 }
 
+func NewAmount(value *big.Int) Amount {
+	var a Amount
+	a.Set(value)
+	return a
+}
+
 func (a *Amount) MarshalJSON() ([]byte, error) {
 	return []byte(a.String()), nil
 }
@@ -23,3 +31,29 @@ func (a *Amount) UnmarshalJSON(b []byte) error {
 	a.SetString(val, 10)
 	return nil
 }
+
+// Value - stores amount as postgres numeric
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan - reads amount from postgres numeric
+func (a *Amount) Scan(src interface{}) error {
+	var raw string
+	switch v := src.(type) {
+	case []byte:
+		raw = string(v)
+	case string:
+		raw = v
+	case int64:
+		a.SetInt64(v)
+		return nil
+	default:
+		return fmt.Errorf("unsupported amount type %T", src)
+	}
+
+	if _, ok := a.SetString(raw, 10); !ok {
+		return fmt.Errorf("invalid amount %q", raw)
+	}
+	return nil
+}
@@ -1,17 +1,96 @@
 package chains
 
 import (
+	"errors"
 	"math/big"
+	"time"
 )
 
+// ErrInvalidSignature - signature was not made by the address owner
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type TxStatus string
+
+const (
+	// TxStatusPending - transaction is known to the network but not finalized yet
+	TxStatusPending TxStatus = "pending"
+	TxStatusSuccess TxStatus = "success"
+	TxStatusFailed  TxStatus = "failed"
+	// TxStatusNotFound - network does not know the transaction, it was dropped or never broadcast
+	TxStatusNotFound TxStatus = "not_found"
+)
+
+// Tx is a signed transaction, its hash is known before broadcast
+type Tx interface {
+	Hash() string
+}
+
+// NetworkHashTx is transaction hash of which is assigned by the network on broadcast, e.g. airdrop signed by the cluster,
+// payouts are reserved with placeholder returned by Hash until then
+type NetworkHashTx interface {
+	Tx
+	// NetworkHash is the hash assigned on successful Broadcast
+	NetworkHash() string
+}
+
+// BroadcastHash is hash of broadcast transaction
+func BroadcastHash(tx Tx) string {
+	if hashed, ok := tx.(NetworkHashTx); ok {
+		return hashed.NetworkHash()
+	}
+	return tx.Hash()
+}
+
 type Chain interface {
 	ID() string
 	Name() string
 	Kind() string
 	NativeToken() string
-	Decimals() float64
+	Decimals() uint8
 	GetBalance(address string, tokenAddress *string) (*big.Int, error)
-	Send(to string, amount *big.Int, tokenAddress *string) (txHash string, err error)
+	Prepare(to string, amount *big.Int, tokenAddress *string) (Tx, error)
+	Broadcast(tx Tx) error
+	TxStatus(txHash string) (TxStatus, error)
+	// VerifySignature checks that message is signed by owner of the address,
+	// publicKey is required for chains where address is not derived from the key
+	VerifySignature(address, message, signature string, publicKey *string) error
+}
+
+// Transfer is a single payout of a batch
+type Transfer struct {
+	To           string
+	Amount       *big.Int
+	TokenAddress *string
+}
+
+// Batcher is implemented by chains preparing many transfers at once with sequential nonces,
+// transactions have to be broadcast in the returned order
+type Batcher interface {
+	// PrepareBatch returns transaction of each transfer, transfers paid together share the transaction
+	PrepareBatch(transfers []Transfer) ([]Tx, error)
+}
+
+// Airdropper is implemented by chains able to fund payouts by network airdrops
+type Airdropper interface {
+	// Airdrops tells whether payouts don't depend on faucet wallet balance
+	Airdrops() bool
+}
+
+// AccountCreator is implemented by chains where receiver account has to be created before it's funded
+type AccountCreator interface {
+	// ValidateNewAccount checks that the faucet can create the account with the public key
+	ValidateNewAccount(to, publicKey string) error
+	// PrepareAccount returns transaction creating the account with the public key and funding it with the amount
+	PrepareAccount(to, publicKey string, amount *big.Int) (Tx, error)
+	// VerifyKeySignature checks that message is signed by the public key, account doesn't have to exist
+	VerifyKeySignature(message, signature, publicKey string) error
+}
+
+// AsyncBroadcaster is implemented by chains whose Broadcast only submits transaction to the network,
+// so payouts stay pending until reconciler finds their transactions executed
+type AsyncBroadcaster interface {
+	// DropTimeout is the time after which transaction still unknown to the network is considered dropped
+	DropTimeout() time.Duration
 }
 
 type Chains map[string]Chain
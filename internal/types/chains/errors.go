@@ -0,0 +1,107 @@
+package chains
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	// ErrInsufficientFunds - faucet wallet can't cover the transfer and fee
+	ErrInsufficientFunds = errors.New("insufficient funds")
+	// ErrInvalidReceiver - network rejects transfer because of the receiver, e.g. it doesn't exist or can't hold the amount
+	ErrInvalidReceiver = errors.New("invalid receiver")
+	ErrRPCUnavailable  = errors.New("rpc unavailable")
+	// ErrNonceConflict - transaction collides with another one of the faucet wallet, it may be retried
+	ErrNonceConflict = errors.New("nonce conflict")
+	// ErrAlreadyKnown - network already holds or has executed the same signed transaction, so it is accepted
+	ErrAlreadyKnown = errors.New("transaction already known")
+	ErrRateLimited  = errors.New("rpc rate limited")
+	// ErrRefilling - faucet wallet is being refilled, it is reported with ErrInsufficientFunds kind and may be retried
+	ErrRefilling = errors.New("faucet wallet is being refilled")
+)
+
+// Error is a failure of known kind, errors.Is matches it against the kind
+type Error struct {
+	Kind error
+	Err  error
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+// IsRejected tells whether broadcast error means the network refused the transaction, so it can't land later.
+// Unavailable rpc or unknown failures may still deliver it, transaction already known by the network is not rejected.
+func IsRejected(err error) bool {
+	return errors.Is(err, ErrInsufficientFunds) || errors.Is(err, ErrInvalidReceiver) || errors.Is(err, ErrNonceConflict)
+}
+
+// acceptKnown drops broadcast error reporting that the network already has the transaction, so resending is not a failure
+func acceptKnown(err error) error {
+	if errors.Is(err, ErrAlreadyKnown) {
+		return nil
+	}
+	return err
+}
+
+// errorRule classifies rpc errors by their messages, as nodes report them only as text
+type errorRule struct {
+	kind      error
+	fragments []string
+}
+
+var commonErrorRules = []errorRule{
+	{ErrRateLimited, []string{"status code: 429", "too many requests", "rate limit"}},
+	{ErrRPCUnavailable, []string{
+		"status code: 502", "status code: 503", "status code: 504",
+		"connection refused", "connection reset", "no such host", "i/o timeout", "eof",
+	}},
+}
+
+// classifyError wraps err into Error if its kind is recognized by chain specific or common rules
+func classifyError(err error, rules []errorRule) error {
+	if err == nil {
+		return nil
+	}
+	var known *Error
+	if errors.As(err, &known) {
+		return err
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		switch {
+		case httpErr.StatusCode == 429:
+			return &Error{Kind: ErrRateLimited, Err: err}
+		case httpErr.StatusCode >= 500:
+			return &Error{Kind: ErrRPCUnavailable, Err: err}
+		}
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, rule := range append(rules, commonErrorRules...) {
+		for _, fragment := range rule.fragments {
+			if strings.Contains(message, fragment) {
+				return &Error{Kind: rule.kind, Err: err}
+			}
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, context.DeadlineExceeded) {
+		return &Error{Kind: ErrRPCUnavailable, Err: err}
+	}
+	return err
+}
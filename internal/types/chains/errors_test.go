@@ -0,0 +1,90 @@
+package chains
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+func TestClassifyError(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		rules []errorRule
+		want  error
+	}{
+		{"evm already known", errors.New("already known"), evmErrorRules, ErrAlreadyKnown},
+		{"evm nonce too low", errors.New("nonce too low: next nonce 5, tx nonce 4"), evmErrorRules, ErrNonceConflict},
+		{"evm underpriced replacement", errors.New("replacement transaction underpriced"), evmErrorRules, ErrNonceConflict},
+		{"evm insufficient funds", errors.New("insufficient funds for gas * price + value"), evmErrorRules, ErrInsufficientFunds},
+		{"solana already processed", errors.New("Transaction simulation failed: This transaction has already been processed"), solanaErrorRules, ErrAlreadyKnown},
+		{"solana blockhash not found", errors.New("Transaction simulation failed: Blockhash not found"), solanaErrorRules, ErrNonceConflict},
+		{"solana rent", errors.New("Transaction results in an account with insufficient funds for rent"), solanaErrorRules, ErrInvalidReceiver},
+		{"solana insufficient lamports", errors.New("Transfer: insufficient lamports 10, need 20"), solanaErrorRules, ErrInsufficientFunds},
+		{"near invalid nonce", errors.New("InvalidNonce"), nearErrorRules, ErrNonceConflict},
+		{"rate limited", errors.New("429 Too Many Requests"), evmErrorRules, ErrRateLimited},
+		{"http 503", rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, evmErrorRules, ErrRPCUnavailable},
+		{"connection refused", errors.New("dial tcp: connection refused"), solanaErrorRules, ErrRPCUnavailable},
+		{"already classified", &Error{Kind: ErrInvalidReceiver, Err: errors.New("nonce too low")}, evmErrorRules, ErrInvalidReceiver},
+		{"wrapped", fmt.Errorf("failed to send: %w", errors.New("already known")), evmErrorRules, ErrAlreadyKnown},
+		{"unknown", errors.New("execution reverted"), evmErrorRules, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := classifyError(tc.err, tc.rules)
+			var known *Error
+			if tc.want == nil {
+				if errors.As(got, &known) {
+					t.Fatalf("classifyError(%q) = %v, want unclassified", tc.err, known.Kind)
+				}
+				return
+			}
+			if !errors.Is(got, tc.want) {
+				t.Fatalf("classifyError(%q) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+
+	if err := classifyError(nil, evmErrorRules); err != nil {
+		t.Fatalf("classifyError(nil) = %v, want nil", err)
+	}
+}
+
+func TestIsRejected(t *testing.T) {
+	cases := []struct {
+		name  string
+		err   error
+		rules []errorRule
+		want  bool
+	}{
+		{"evm already known", errors.New("already known"), evmErrorRules, false},
+		{"solana already processed", errors.New("This transaction has already been processed"), solanaErrorRules, false},
+		{"evm nonce too low", errors.New("nonce too low"), evmErrorRules, true},
+		{"solana blockhash not found", errors.New("Blockhash not found"), solanaErrorRules, true},
+		{"insufficient funds", errors.New("insufficient funds for transfer"), evmErrorRules, true},
+		{"invalid receiver", errors.New("AccountDoesNotExist"), nearErrorRules, true},
+		{"rpc unavailable", errors.New("unexpected EOF"), evmErrorRules, false},
+		{"rate limited", errors.New("rate limit exceeded"), solanaErrorRules, false},
+		{"unknown", errors.New("execution reverted"), evmErrorRules, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := IsRejected(classifyError(tc.err, tc.rules)); got != tc.want {
+				t.Errorf("IsRejected(%q) = %v, want %v", tc.err, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAcceptKnown(t *testing.T) {
+	if err := acceptKnown(classifyError(errors.New("already known"), evmErrorRules)); err != nil {
+		t.Errorf("acceptKnown(already known) = %v, want nil", err)
+	}
+	if err := acceptKnown(classifyError(errors.New("nonce too low"), evmErrorRules)); !errors.Is(err, ErrNonceConflict) {
+		t.Errorf("acceptKnown(nonce too low) = %v, want nonce conflict", err)
+	}
+}
@@ -6,27 +6,48 @@ import (
 	"faucet-svc/internal/contracts"
 	types2 "faucet-svc/internal/types"
 	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
 	"math/big"
+	"strings"
+	"sync"
+	"time"
 )
 
+var evmErrorRules = []errorRule{
+	{ErrInsufficientFunds, []string{"insufficient funds", "transfer amount exceeds balance"}},
+	{ErrAlreadyKnown, []string{"already known"}},
+	{ErrNonceConflict, []string{"nonce too low", "nonce too high", "replacement transaction underpriced"}},
+}
+
+// evmUnsentTimeout is how long nonce of prepared transaction is kept from reuse until it is broadcast,
+// nonces of transactions abandoned before broadcast are reused after it, so they don't leave gaps
+const evmUnsentTimeout = time.Minute
+
 type evmChain struct {
 	client      *ethclient.Client
 	signer      types2.EvmSigner
 	id          string
 	name        string
 	kind        string
-	decimals    float64
+	decimals    uint8
 	nativeToken string
 	rpc         string
+	// disperse is address of disperse.app compatible contract, nil if batches are paid by separate transactions
+	disperse *common.Address
+
+	// mu guards unsent, nonces of transactions prepared but not broadcast yet by the time they were prepared
+	mu     sync.Mutex
+	unsent map[uint64]time.Time
 }
 
-func NewEvmChain(client *ethclient.Client, signer types2.EvmSigner, id, name, nativeToken, rpc string, decimals float64) Chain {
+func NewEvmChain(client *ethclient.Client, signer types2.EvmSigner, id, name, nativeToken, rpc string, decimals uint8, disperse *common.Address) Chain {
 	return &evmChain{
 		client:      client,
 		signer:      signer,
@@ -36,6 +57,8 @@ func NewEvmChain(client *ethclient.Client, signer types2.EvmSigner, id, name, na
 		decimals:    decimals,
 		nativeToken: nativeToken,
 		rpc:         rpc,
+		disperse:    disperse,
+		unsent:      make(map[uint64]time.Time),
 	}
 }
 
@@ -55,7 +78,7 @@ func (c *evmChain) NativeToken() string {
 	return c.nativeToken
 }
 
-func (c *evmChain) Decimals() float64 {
+func (c *evmChain) Decimals() uint8 {
 	return c.decimals
 }
 
@@ -67,25 +90,195 @@ func (c *evmChain) GetBalance(address string, tokenAddress *string) (balance *bi
 			return &big.Int{}, err
 		}
 		balance, err = contract.BalanceOf(&bind.CallOpts{}, addr)
-		return balance, err
+		return balance, classifyError(err, evmErrorRules)
 	}
 	balance, err = c.client.BalanceAt(context.Background(), addr, nil)
-	return
+	return balance, classifyError(err, evmErrorRules)
 }
 
-func (c *evmChain) Send(to string, amount *big.Int, tokenAddress *string) (txHash string, err error) {
-	var tknAddr *common.Address
-	if tokenAddress != nil {
-		addr := common.HexToAddress(*tokenAddress)
-		tknAddr = &addr
+type evmTx struct {
+	signed *types.Transaction
+}
+
+func (t evmTx) Hash() string {
+	return t.signed.Hash().String()
+}
+
+func (c *evmChain) Prepare(to string, amount *big.Int, tokenAddress *string) (Tx, error) {
+	nonce, err := c.reserveNonces(1)
+	if err != nil {
+		return nil, classifyError(err, evmErrorRules)
 	}
 
-	signedTx, err := c.buildTx(common.HexToAddress(to), *amount, tknAddr)
+	signedTx, err := c.buildTx(nonce, common.HexToAddress(to), *amount, toEvmTokenAddress(tokenAddress))
 	if err != nil {
-		return
+		c.releaseNonces(nonce, 1)
+		return nil, classifyError(err, evmErrorRules)
+	}
+	return evmTx{signed: signedTx}, nil
+}
+
+// PrepareBatch signs transfers with sequential nonces, if disperse contract is configured,
+// transfers of the same currency are paid by a single transaction
+func (c *evmChain) PrepareBatch(transfers []Transfer) ([]Tx, error) {
+	groups := c.groupTransfers(transfers)
+	first, err := c.reserveNonces(len(groups))
+	if err != nil {
+		return nil, classifyError(err, evmErrorRules)
+	}
+
+	nonce := first
+	result := make([]Tx, len(transfers))
+	for _, group := range groups {
+		var signedTx *types.Transaction
+		if len(group) == 1 {
+			transfer := transfers[group[0]]
+			signedTx, err = c.buildTx(nonce, common.HexToAddress(transfer.To), *transfer.Amount, toEvmTokenAddress(transfer.TokenAddress))
+		} else {
+			signedTx, err = c.buildDisperseTx(nonce, transfers, group)
+		}
+		if err != nil {
+			c.releaseNonces(first, len(groups))
+			return nil, classifyError(err, evmErrorRules)
+		}
+
+		nonce++
+		for _, i := range group {
+			result[i] = evmTx{signed: signedTx}
+		}
+	}
+	return result, nil
+}
+
+// groupTransfers returns indexes of transfers paid by the same transaction in order of their first transfer,
+// without disperse contract each transfer is paid separately
+func (c *evmChain) groupTransfers(transfers []Transfer) [][]int {
+	var groups [][]int
+	currencies := make(map[string]int)
+	for i, transfer := range transfers {
+		if c.disperse == nil {
+			groups = append(groups, []int{i})
+			continue
+		}
+
+		currency := ""
+		if transfer.TokenAddress != nil {
+			currency = strings.ToLower(*transfer.TokenAddress)
+		}
+		group, ok := currencies[currency]
+		if !ok {
+			group = len(groups)
+			currencies[currency] = group
+			groups = append(groups, nil)
+		}
+		groups[group] = append(groups[group], i)
+	}
+	return groups
+}
+
+// buildDisperseTx pays transfers of the group with disperse contract, transfers must have the same currency.
+// Tokens are taken from faucet wallet by the contract, so it has to be approved to spend them.
+func (c *evmChain) buildDisperseTx(nonce uint64, transfers []Transfer, group []int) (*types.Transaction, error) {
+	contract, err := contracts.NewDisperseTransactor(*c.disperse, c.client)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := bind.NewKeyedTransactorWithChainID(c.signer.PrivKey(), c.chainID())
+	if err != nil {
+		return nil, err
+	}
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.NoSend = true
+
+	recipients := make([]common.Address, 0, len(group))
+	values := make([]*big.Int, 0, len(group))
+	total := big.NewInt(0)
+	for _, i := range group {
+		recipients = append(recipients, common.HexToAddress(transfers[i].To))
+		values = append(values, transfers[i].Amount)
+		total.Add(total, transfers[i].Amount)
+	}
+
+	tokenAddress := transfers[group[0]].TokenAddress
+	if tokenAddress == nil {
+		opts.Value = total
+		return contract.DisperseEther(opts, recipients, values)
+	}
+	return contract.DisperseToken(opts, common.HexToAddress(*tokenAddress), recipients, values)
+}
+
+func (c *evmChain) Broadcast(tx Tx) error {
+	signed := tx.(evmTx).signed
+	err := acceptKnown(classifyError(c.client.SendTransaction(context.Background(), signed), evmErrorRules))
+	if err == nil || IsRejected(err) {
+		// nonce is either counted by the network as pending or is free to be reused,
+		// transaction which may be delivered keeps it until evmUnsentTimeout
+		c.releaseNonces(signed.Nonce(), 1)
 	}
-	err = c.client.SendTransaction(context.Background(), signedTx)
-	return signedTx.Hash().String(), err
+	return err
+}
+
+// reserveNonces reserves count sequential nonces for transactions being prepared and returns the first of them.
+// Nonces are counted from the network pending nonce and ones of transactions not broadcast yet,
+// so concurrent payouts and batches don't reuse them.
+func (c *evmChain) reserveNonces(count int) (uint64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	pending, err := c.client.PendingNonceAt(context.Background(), c.signer.Address())
+	if err != nil {
+		return 0, err
+	}
+
+	next := pending
+	for nonce, preparedAt := range c.unsent {
+		if nonce < pending || time.Since(preparedAt) > evmUnsentTimeout {
+			delete(c.unsent, nonce)
+			continue
+		}
+		if nonce >= next {
+			next = nonce + 1
+		}
+	}
+
+	now := time.Now()
+	for i := 0; i < count; i++ {
+		c.unsent[next+uint64(i)] = now
+	}
+	return next, nil
+}
+
+// releaseNonces drops count sequential nonces starting from the first one from unsent
+func (c *evmChain) releaseNonces(first uint64, count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := 0; i < count; i++ {
+		delete(c.unsent, first+uint64(i))
+	}
+}
+
+func (c *evmChain) TxStatus(txHash string) (TxStatus, error) {
+	hash := common.HexToHash(txHash)
+	receipt, err := c.client.TransactionReceipt(context.Background(), hash)
+	if err == nil {
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			return TxStatusSuccess, nil
+		}
+		return TxStatusFailed, nil
+	}
+	if !errors.Is(err, ethereum.NotFound) {
+		return "", err
+	}
+
+	_, _, err = c.client.TransactionByHash(context.Background(), hash)
+	if errors.Is(err, ethereum.NotFound) {
+		return TxStatusNotFound, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return TxStatusPending, nil
 }
 
 func (c *evmChain) getGasPrice(to common.Address, data []byte) (gasPrice *big.Int, gasLimit uint64, err error) {
@@ -101,12 +294,13 @@ func (c *evmChain) getGasPrice(to common.Address, data []byte) (gasPrice *big.In
 	return
 }
 
-func (c *evmChain) buildTx(to common.Address, amount big.Int, tokenAddress *common.Address) (signedTx *types.Transaction, err error) {
-	nonce, err := c.client.PendingNonceAt(context.Background(), c.signer.Address())
-	if err != nil {
-		return
-	}
+func (c *evmChain) chainID() *big.Int {
+	cid := big.NewInt(0)
+	cid.SetString(c.ID(), 10)
+	return cid
+}
 
+func (c *evmChain) buildTx(nonce uint64, to common.Address, amount big.Int, tokenAddress *common.Address) (signedTx *types.Transaction, err error) {
 	transferFnSignature := []byte("transfer(address,uint256)")
 	hash := crypto.NewKeccakState()
 	_, err = hash.Write(transferFnSignature)
@@ -145,12 +339,39 @@ func (c *evmChain) buildTx(to common.Address, amount big.Int, tokenAddress *comm
 
 	tx := types.NewTx(&txData)
 
-	cid := big.NewInt(0)
-	cid.SetString(c.ID(), 10)
-	signedTx, err = types.SignTx(tx, types.NewEIP155Signer(cid), c.signer.PrivKey())
+	signedTx, err = types.SignTx(tx, types.NewEIP155Signer(c.chainID()), c.signer.PrivKey())
 	return
 }
 
+func toEvmTokenAddress(tokenAddress *string) *common.Address {
+	if tokenAddress == nil {
+		return nil
+	}
+	addr := common.HexToAddress(*tokenAddress)
+	return &addr
+}
+
+// VerifySignature checks EIP-191 personal_sign signature in hex
+func (c *evmChain) VerifySignature(address, message, signature string, _ *string) error {
+	sig, err := hexutil.Decode(signature)
+	if err != nil || len(sig) != crypto.SignatureLength {
+		return ErrInvalidSignature
+	}
+	// wallets return recovery id as 27 or 28
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(message)), sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if crypto.PubkeyToAddress(*pubKey) != common.HexToAddress(address) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
 func ValidateEvmAddress(value interface{}) error {
 	err := validation.Validate(value.(string), validation.Length(40, 42))
 	if err != nil {
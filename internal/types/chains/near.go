@@ -2,41 +2,103 @@ package chains
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"faucet-svc/internal/types"
+	"fmt"
 	uint128 "github.com/eteu-technologies/golang-uint128"
 	"github.com/eteu-technologies/near-api-go/pkg/client"
 	"github.com/eteu-technologies/near-api-go/pkg/client/block"
 	types2 "github.com/eteu-technologies/near-api-go/pkg/types"
 	"github.com/eteu-technologies/near-api-go/pkg/types/action"
+	"github.com/eteu-technologies/near-api-go/pkg/types/hash"
+	"github.com/eteu-technologies/near-api-go/pkg/types/key"
 	"github.com/eteu-technologies/near-api-go/pkg/types/transaction"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mr-tron/base58"
 	"math/big"
 	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
+var nearErrorRules = []errorRule{
+	{ErrInsufficientFunds, []string{"notenoughbalance", "lackbalanceforstate"}},
+	{ErrInvalidReceiver, []string{"accountdoesnotexist", "accountalreadyexists", "does not exist while viewing", "unknown_account"}},
+	{ErrNonceConflict, []string{"invalidnonce"}},
+}
+
+const (
+	// NearAccountCreationSubAccount - receivers are created as sub-accounts of the faucet account
+	NearAccountCreationSubAccount = "sub_account"
+	// NearAccountCreationRegistrar - receivers are created as top level accounts by the registrar contract
+	NearAccountCreationRegistrar = "registrar"
+)
+
+// nearCreateAccountGas is attached to create_account call of the registrar, unused gas is refunded
+const nearCreateAccountGas types2.Gas = 100_000_000_000_000
+
+const (
+	// nearNonceRefresh is how often nonce counter of access key catches up with the network, in case the key is used elsewhere
+	nearNonceRefresh = time.Minute
+	// nearDropTimeout bounds time transaction broadcast asynchronously takes to get into a block
+	nearDropTimeout = 2 * time.Minute
+)
+
+// nearFalseValue is base64 of false returned by create_account of the registrar if account wasn't created
+const nearFalseValue = "ZmFsc2U="
+
+// NearOptions configures optional behaviour of the near chain
+type NearOptions struct {
+	// AccountCreation is the way receiver accounts are created, empty disables creation
+	AccountCreation string
+	// Registrar is the account of registrar contract used by NearAccountCreationRegistrar
+	Registrar string
+}
+
+// nearAccessKey signs transactions with locally counted nonces, so concurrent payouts don't reuse them
+type nearAccessKey struct {
+	keyPair  key.KeyPair
+	mu       sync.Mutex
+	nonce    types2.Nonce
+	loadedAt time.Time
+}
+
 type nearChain struct {
 	client      *client.Client
 	signer      types.NearSigner
+	keys        []*nearAccessKey
+	next        uint32
 	id          string
 	name        string
 	kind        string
-	decimals    float64
+	decimals    uint8
 	nativeToken string
 	rpc         string
+	opts        NearOptions
 }
 
-func NewNearChain(client *client.Client, signer types.NearSigner, id, rpc, nativeToken string, decimals float64) Chain {
+func NewNearChain(client *client.Client, signer types.NearSigner, id, rpc, nativeToken string, decimals uint8, opts NearOptions) Chain {
+	keys := make([]*nearAccessKey, 0, len(signer.KeyPairs()))
+	for _, keyPair := range signer.KeyPairs() {
+		keys = append(keys, &nearAccessKey{keyPair: keyPair})
+	}
+
 	return &nearChain{
 		client:      client,
 		signer:      signer,
+		keys:        keys,
 		id:          id,
 		name:        "Near " + id,
 		kind:        "near",
 		decimals:    decimals,
 		nativeToken: nativeToken,
 		rpc:         rpc,
+		opts:        opts,
 	}
 }
 
@@ -56,38 +118,145 @@ func (c *nearChain) NativeToken() string {
 	return c.nativeToken
 }
 
-func (c *nearChain) Decimals() float64 {
+func (c *nearChain) Decimals() uint8 {
 	return c.decimals
 }
 
 func (c *nearChain) GetBalance(address string, _ *string) (balance *big.Int, err error) {
 	account, err := c.getAccountInfo(address)
 	if err != nil {
-		return
+		return nil, classifyError(err, nearErrorRules)
 	}
 	balance = &account.Amount.Int
 	return
 }
 
-func (c *nearChain) Send(to string, amount *big.Int, _ *string) (txHash string, err error) {
-	tx, err := c.buildTx(to, amount)
+type nearTx struct {
+	signed transaction.SignedTransaction
+}
+
+func (t nearTx) Hash() string {
+	return t.signed.Hash().String()
+}
+
+func (c *nearChain) Prepare(to string, amount *big.Int, _ *string) (Tx, error) {
+	signedTx, err := c.buildTx(to, action.NewTransfer(toNearBalance(amount)))
 	if err != nil {
-		return
+		return nil, classifyError(err, nearErrorRules)
+	}
+	return nearTx{signed: signedTx}, nil
+}
+
+// ValidateNewAccount checks that the account can be created by the faucet with the public key,
+// implicit accounts are created by a plain transfer, so they can't be requested with a key
+func (c *nearChain) ValidateNewAccount(to, publicKey string) error {
+	if _, err := parseNearPublicKey(publicKey); err != nil {
+		return errors.New("public key must be ed25519 key in base58 with ed25519: prefix")
 	}
-	txRes, err := c.client.RPCTransactionSendAwait(
+
+	var parent string
+	switch c.opts.AccountCreation {
+	case NearAccountCreationSubAccount:
+		parent = c.signer.ID()
+	case NearAccountCreationRegistrar:
+		parent = c.opts.Registrar
+	default:
+		return errors.New("account creation is not enabled for the chain")
+	}
+
+	if isNearImplicitAccount(to) {
+		return errors.New("implicit account is created by transfer, public key must not be set")
+	}
+	name := strings.TrimSuffix(to, "."+parent)
+	if name == to || name == "" || strings.Contains(name, ".") {
+		return fmt.Errorf("only direct sub-accounts of %s can be created", parent)
+	}
+	return nil
+}
+
+// PrepareAccount creates the account with full access public key and funds it with the amount in a single transaction
+func (c *nearChain) PrepareAccount(to, publicKey string, amount *big.Int) (Tx, error) {
+	pubKey, err := parseNearPublicKey(publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var signedTx transaction.SignedTransaction
+	switch c.opts.AccountCreation {
+	case NearAccountCreationSubAccount:
+		signedTx, err = c.buildTx(to,
+			action.NewCreateAccount(),
+			newNearFullAccessKey(pubKey.ToPublicKey()),
+			action.NewTransfer(toNearBalance(amount)),
+		)
+	case NearAccountCreationRegistrar:
+		var args []byte
+		args, err = json.Marshal(map[string]string{
+			"new_account_id": to,
+			"new_public_key": pubKey.String(),
+		})
+		if err != nil {
+			return nil, err
+		}
+		signedTx, err = c.buildTx(c.opts.Registrar,
+			action.NewFunctionCall("create_account", args, nearCreateAccountGas, toNearBalance(amount)),
+		)
+	default:
+		return nil, errors.New("account creation is not enabled for the chain")
+	}
+	if err != nil {
+		return nil, classifyError(err, nearErrorRules)
+	}
+	return nearTx{signed: signedTx}, nil
+}
+
+func (c *nearChain) Broadcast(tx Tx) error {
+	serializedTx, err := tx.(nearTx).signed.Serialize()
+	if err != nil {
+		return err
+	}
+
+	// transaction is only submitted, its result is polled by TxStatus later
+	_, err = c.client.RPCTransactionSend(
 		context.Background(),
-		tx,
+		serializedTx,
 	)
 	if err != nil {
-		return
+		return classifyError(err, nearErrorRules)
 	}
+	return nil
+}
 
-	if failMessage := string(txRes.Status.Failure); failMessage != "" {
-		err = errors.New(failMessage)
-		return
+// DropTimeout implements AsyncBroadcaster, transaction rejected by the network is never known to it
+func (c *nearChain) DropTimeout() time.Duration {
+	return nearDropTimeout
+}
+
+func (c *nearChain) TxStatus(txHash string) (TxStatus, error) {
+	txHashBytes, err := hash.NewCryptoHashFromBase58(txHash)
+	if err != nil {
+		return "", err
+	}
+
+	txRes, err := c.client.TransactionStatus(context.Background(), txHashBytes, c.signer.ID())
+	if err != nil {
+		if strings.Contains(err.Error(), "UNKNOWN_TRANSACTION") {
+			return TxStatusNotFound, nil
+		}
+		if strings.Contains(err.Error(), "TIMEOUT_ERROR") {
+			// transaction is known but not executed yet
+			return TxStatusPending, nil
+		}
+		return "", err
+	}
+
+	// outcome is returned once transaction is executed, success value of a transfer is empty
+	switch {
+	case len(txRes.Status.Failure) != 0, txRes.Status.SuccessValue == nearFalseValue:
+		return TxStatusFailed, nil
+	default:
+		return TxStatusSuccess, nil
 	}
-	txHash = txRes.Transaction.Hash.String()
-	return
 }
 
 func (c *nearChain) getAccountInfo(id string) (acc types.AccountInfo, err error) {
@@ -99,47 +268,143 @@ func (c *nearChain) getAccountInfo(id string) (acc types.AccountInfo, err error)
 	return
 }
 
-func (c *nearChain) buildTx(receiverId string, amount *big.Int) (serializedTx string, err error) {
-	pubKey := c.signer.KeyPair().PublicKey
-
-	accessKey, err := c.client.AccessKeyView(context.Background(), c.signer.ID(), pubKey, block.FinalityFinal())
+func (c *nearChain) buildTx(receiverId string, actions ...action.Action) (signedTx transaction.SignedTransaction, err error) {
+	blockDetails, err := c.client.BlockDetails(context.Background(), block.FinalityFinal())
 	if err != nil {
 		return
 	}
 
-	blockDetails, err := c.client.BlockDetails(context.Background(), block.FinalityFinal())
+	accessKey := c.nextKey()
+	nonce, err := c.nextNonce(accessKey)
 	if err != nil {
 		return
 	}
 
 	txn := transaction.Transaction{
-		PublicKey:  pubKey.ToPublicKey(),
+		PublicKey:  accessKey.keyPair.PublicKey.ToPublicKey(),
 		SignerID:   c.signer.ID(),
-		Nonce:      accessKey.Nonce + 1,
+		Nonce:      nonce,
 		ReceiverID: receiverId,
-		Actions: []action.Action{
-			action.NewTransfer(
-				types2.Balance(uint128.FromBig(amount)),
-			),
-		},
-		BlockHash: blockDetails.Header.Hash,
+		Actions:    actions,
+		BlockHash:  blockDetails.Header.Hash,
+	}
+
+	signedTx, err = transaction.NewSignedTransaction(accessKey.keyPair, txn)
+	return
+}
+
+// nextKey returns access keys in turns, so payouts signed with different keys don't conflict
+func (c *nearChain) nextKey() *nearAccessKey {
+	return c.keys[atomic.AddUint32(&c.next, 1)%uint32(len(c.keys))]
+}
+
+// nextNonce reserves nonce of the access key, the counter is loaded from the network on first use and every nearNonceRefresh,
+// the greater of local and network nonces is kept, as transactions signed locally may be not executed yet
+func (c *nearChain) nextNonce(accessKey *nearAccessKey) (types2.Nonce, error) {
+	accessKey.mu.Lock()
+	defer accessKey.mu.Unlock()
+
+	if time.Since(accessKey.loadedAt) > nearNonceRefresh {
+		view, err := c.client.AccessKeyView(context.Background(), c.signer.ID(), accessKey.keyPair.PublicKey, block.FinalityFinal())
+		if err != nil {
+			return 0, err
+		}
+		if view.Nonce > accessKey.nonce {
+			accessKey.nonce = view.Nonce
+		}
+		accessKey.loadedAt = time.Now()
+	}
+
+	accessKey.nonce++
+	return accessKey.nonce, nil
+}
+
+func toNearBalance(amount *big.Int) types2.Balance {
+	return types2.Balance(uint128.FromBig(amount))
+}
+
+// newNearFullAccessKey builds AddKey action by hand, as action.NewAddKey of the sdk drops its arguments
+// and its full access permission is encoded as the function call one
+func newNearFullAccessKey(publicKey key.PublicKey) action.Action {
+	addKey := action.NewAddKey(publicKey, 0, action.NewFullAccessPermission())
+	addKey.AddKey.PublicKey = publicKey
+	addKey.AddKey.AccessKey.Permission.Enum = 1
+	return addKey
+}
+
+func parseNearPublicKey(publicKey string) (key.Base58PublicKey, error) {
+	pubKey, err := key.NewBase58PublicKey(publicKey)
+	if err != nil {
+		return pubKey, err
+	}
+	if pubKey.Type != key.KeyTypeED25519 {
+		return pubKey, errors.New("only ed25519 keys are supported")
+	}
+	return pubKey, nil
+}
+
+// isNearImplicitAccount tells whether the account is hex of ed25519 public key, such accounts exist once funded
+func isNearImplicitAccount(address string) bool {
+	return nearImplicitAccountRegexp.MatchString(address)
+}
+
+// VerifySignature checks base58 ed25519 signature of the message made by the public key,
+// which has to be an access key of the account, implicit account may not exist yet and is its public key itself
+func (c *nearChain) VerifySignature(address, message, signature string, publicKey *string) error {
+	if publicKey == nil {
+		return ErrInvalidSignature
+	}
+	if err := c.VerifyKeySignature(message, signature, *publicKey); err != nil {
+		return err
+	}
+
+	pubKey, _ := parseNearPublicKey(*publicKey)
+	if address == hex.EncodeToString(pubKey.ToPublicKey().Value()) {
+		return nil
 	}
 
-	signedTx, err := transaction.NewSignedTransaction(c.signer.KeyPair(), txn)
+	keys, err := c.client.AccessKeyViewList(context.Background(), address, block.FinalityFinal())
 	if err != nil {
-		return
+		if strings.Contains(err.Error(), "UNKNOWN_ACCOUNT") {
+			return ErrInvalidSignature
+		}
+		return err
+	}
+	for _, accessKey := range keys.Keys {
+		if accessKey.PublicKey.String() == pubKey.String() {
+			return nil
+		}
 	}
+	return ErrInvalidSignature
+}
 
-	serializedTx, err = signedTx.Serialize()
-	return
+// VerifyKeySignature checks base58 ed25519 signature of the message made by the public key
+func (c *nearChain) VerifyKeySignature(message, signature, publicKey string) error {
+	pubKey, err := parseNearPublicKey(publicKey)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	sig, err := base58.Decode(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+
+	if !ed25519.Verify(pubKey.ToPublicKey().Value(), []byte(message), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
 }
 
+var (
+	nearAccountRegexp         = regexp.MustCompile(`^(([a-z\d]+[\-_])*[a-z\d]+\.)*([a-z\d]+[\-_])*[a-z\d]+$`)
+	nearImplicitAccountRegexp = regexp.MustCompile(`^[0-9a-f]{64}$`)
+)
+
+// ValidateNearAddress accepts named accounts of any level and implicit accounts by the near account id rules
 func ValidateNearAddress(value interface{}) error {
 	return validation.Validate(
 		value.(string),
 		validation.Length(2, 64),
-		validation.Match(
-			regexp.MustCompile("^[a-z-_0-9]{2,56}(.testnet|.near)?$"),
-		),
+		validation.Match(nearAccountRegexp),
 	)
 }
@@ -0,0 +1,209 @@
+package chains
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eteu-technologies/near-api-go/pkg/client"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/mr-tron/base58"
+)
+
+const signedMessage = "faucet ownership nonce 42"
+
+func TestEvmVerifySignature(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	sign := func(key *ecdsa.PrivateKey, message string, walletRecoveryID bool) string {
+		sig, err := crypto.Sign(accounts.TextHash([]byte(message)), key)
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		if walletRecoveryID {
+			sig[crypto.RecoveryIDOffset] += 27
+		}
+		return hexutil.Encode(sig)
+	}
+	signature := sign(key, signedMessage, true)
+
+	cases := []struct {
+		name      string
+		address   string
+		message   string
+		signature string
+		wantErr   bool
+	}{
+		{"wallet recovery id", address.Hex(), signedMessage, signature, false},
+		{"raw recovery id", address.Hex(), signedMessage, sign(key, signedMessage, false), false},
+		{"lowercase address", strings.ToLower(address.Hex()), signedMessage, signature, false},
+		{"another address", crypto.PubkeyToAddress(other.PublicKey).Hex(), signedMessage, signature, true},
+		{"another message", address.Hex(), "faucet ownership nonce 43", signature, true},
+		{"signed by another key over the same message", address.Hex(), signedMessage, sign(other, signedMessage, true), true},
+		{"truncated signature", address.Hex(), signedMessage, signature[:len(signature)-2], true},
+		{"not hex", address.Hex(), signedMessage, "signature", true},
+		{"empty", address.Hex(), signedMessage, "", true},
+	}
+
+	chain := &evmChain{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := chain.VerifySignature(tc.address, tc.message, tc.signature, nil)
+			checkSignatureErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestSolanaVerifySignature(t *testing.T) {
+	pub, priv := mustEd25519Key(t)
+	otherPub, otherPriv := mustEd25519Key(t)
+	address := base58.Encode(pub)
+	signature := base58.Encode(ed25519.Sign(priv, []byte(signedMessage)))
+
+	cases := []struct {
+		name      string
+		address   string
+		message   string
+		signature string
+		wantErr   bool
+	}{
+		{"valid", address, signedMessage, signature, false},
+		{"another address", base58.Encode(otherPub), signedMessage, signature, true},
+		{"signed by another key", address, signedMessage, base58.Encode(ed25519.Sign(otherPriv, []byte(signedMessage))), true},
+		{"another message", address, "faucet ownership nonce 43", signature, true},
+		{"address case changed", strings.ToLower(address), signedMessage, signature, true},
+		{"truncated signature", address, signedMessage, signature[:len(signature)-4], true},
+		{"not base58", address, signedMessage, "0OIl", true},
+		{"invalid address", "address", signedMessage, signature, true},
+	}
+
+	chain := &solanaChain{}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := chain.VerifySignature(tc.address, tc.message, tc.signature, nil)
+			checkSignatureErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestNearVerifySignature(t *testing.T) {
+	pub, priv := mustEd25519Key(t)
+	otherPub, otherPriv := mustEd25519Key(t)
+	publicKey := nearPublicKey(pub)
+	otherPublicKey := nearPublicKey(otherPub)
+	signature := base58.Encode(ed25519.Sign(priv, []byte(signedMessage)))
+	otherSignature := base58.Encode(ed25519.Sign(otherPriv, []byte(signedMessage)))
+
+	// named accounts are checked against access keys returned by rpc
+	accessKeys := map[string][]string{
+		"alice.testnet": {otherPublicKey, publicKey},
+		"bob.testnet":   {otherPublicKey},
+	}
+	rpc := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     string `json:"id"`
+			Params struct {
+				AccountID string `json:"account_id"`
+			} `json:"params"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Errorf("failed to decode rpc request: %v", err)
+			return
+		}
+
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": request.ID}
+		keys, ok := accessKeys[request.Params.AccountID]
+		if !ok {
+			response["error"] = map[string]interface{}{
+				"code":    -32000,
+				"message": "Server error",
+				"data":    map[string]interface{}{"name": "HANDLER_ERROR", "cause": map[string]string{"name": "UNKNOWN_ACCOUNT"}},
+			}
+		} else {
+			list := make([]map[string]interface{}, 0, len(keys))
+			for _, key := range keys {
+				list = append(list, map[string]interface{}{
+					"public_key": key,
+					"access_key": map[string]interface{}{"nonce": 1, "permission": "FullAccess"},
+				})
+			}
+			response["result"] = map[string]interface{}{"keys": list}
+		}
+		if err := json.NewEncoder(w).Encode(response); err != nil {
+			t.Errorf("failed to encode rpc response: %v", err)
+		}
+	}))
+	defer rpc.Close()
+
+	nearClient, err := client.NewClient(rpc.URL)
+	if err != nil {
+		t.Fatalf("failed to create near client: %v", err)
+	}
+	chain := &nearChain{client: &nearClient}
+
+	cases := []struct {
+		name      string
+		address   string
+		message   string
+		signature string
+		publicKey *string
+		wantErr   bool
+	}{
+		{"implicit account", hex.EncodeToString(pub), signedMessage, signature, &publicKey, false},
+		{"named account access key", "alice.testnet", signedMessage, signature, &publicKey, false},
+		{"key of another account", "bob.testnet", signedMessage, signature, &publicKey, true},
+		{"key of another implicit account", hex.EncodeToString(otherPub), signedMessage, signature, &publicKey, true},
+		{"unknown account", "carol.testnet", signedMessage, signature, &publicKey, true},
+		{"signed by another key", "alice.testnet", signedMessage, otherSignature, &publicKey, true},
+		{"another message", "alice.testnet", "faucet ownership nonce 43", signature, &publicKey, true},
+		{"no public key", hex.EncodeToString(pub), signedMessage, signature, nil, true},
+		{"truncated signature", hex.EncodeToString(pub), signedMessage, signature[:len(signature)-4], &publicKey, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := chain.VerifySignature(tc.address, tc.message, tc.signature, tc.publicKey)
+			checkSignatureErr(t, err, tc.wantErr)
+		})
+	}
+}
+
+func mustEd25519Key(t *testing.T) (ed25519.PublicKey, ed25519.PrivateKey) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return pub, priv
+}
+
+func nearPublicKey(pub ed25519.PublicKey) string {
+	return "ed25519:" + base58.Encode(pub)
+}
+
+func checkSignatureErr(t *testing.T, err error, wantErr bool) {
+	if !wantErr {
+		if err != nil {
+			t.Errorf("VerifySignature() failed: %v", err)
+		}
+		return
+	}
+	if err != ErrInvalidSignature {
+		t.Errorf("VerifySignature() error = %v, want %v", err, ErrInvalidSignature)
+	}
+}
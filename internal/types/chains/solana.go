@@ -2,27 +2,101 @@ package chains
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/mr-tron/base58"
 	"github.com/portto/solana-go-sdk/client"
 	"github.com/portto/solana-go-sdk/common"
+	"github.com/portto/solana-go-sdk/program/compute_budget"
 	"github.com/portto/solana-go-sdk/program/sysprog"
+	"github.com/portto/solana-go-sdk/rpc"
 	"github.com/portto/solana-go-sdk/types"
 	"math/big"
+	"strings"
+	"sync"
+	"time"
 )
 
+var solanaErrorRules = []errorRule{
+	// checked before insufficient funds, as rent failure of receiver account is reported alike
+	{ErrInvalidReceiver, []string{"insufficient funds for rent"}},
+	{ErrInsufficientFunds, []string{"insufficient lamports", "attempt to debit an account but found no record of a prior credit"}},
+	{ErrAlreadyKnown, []string{"already been processed"}},
+	{ErrNonceConflict, []string{"blockhash not found"}},
+	{ErrRateLimited, []string{"airdrop limit", "airdrop request limit", "faucet has run dry"}},
+}
+
+// Solana payout strategies
+const (
+	// SolanaStrategyTransfer - payouts are transferred from faucet wallet
+	SolanaStrategyTransfer = "transfer"
+	// SolanaStrategyAirdrop - payouts are airdropped to receivers by the cluster, devnet and testnet only
+	SolanaStrategyAirdrop = "airdrop"
+	// SolanaStrategyAirdropThenTransfer - faucet wallet is refilled by airdrop when it can't cover a payout
+	SolanaStrategyAirdropThenTransfer = "airdrop_then_transfer"
+)
+
+const (
+	// solanaMaxTxSize is the packet data size limit of serialized transaction
+	solanaMaxTxSize = 1232
+	// solanaTransferComputeUnits is compute budget reserved for a transfer with some margin
+	solanaTransferComputeUnits = 500
+	// solanaComputeBudgetUnits is consumed by compute budget instructions themselves
+	solanaComputeBudgetUnits = 300
+	// solanaFeeReserve is kept in faucet wallet above payouts for transaction fees when it is refilled by airdrops
+	solanaFeeReserve = 1000000
+	// solanaAirdropTimeout limits waiting for refill airdrop to be confirmed
+	solanaAirdropTimeout      = 30 * time.Second
+	solanaAirdropPollInterval = time.Second
+	// solanaRebroadcastTimeout bounds resending, it exceeds the time recent blockhash is valid for (150 blocks, about a minute),
+	// durable nonce transactions are resent as long
+	solanaRebroadcastTimeout = 2 * time.Minute
+	// solanaRebroadcastMaxFailures stops resending once rpc keeps failing, reconciler resolves the payout then
+	solanaRebroadcastMaxFailures = 10
+)
+
+// SolanaOptions tune how payouts are sent on solana chain
+type SolanaOptions struct {
+	// Strategy is how payouts are funded, one of SolanaStrategy constants
+	Strategy string
+	// PriorityFee is compute unit price in micro-lamports, 0 disables priority fees
+	PriorityFee uint64
+	// RetryInterval is how often unconfirmed transactions are resent until their blockhash expires, 0 disables resending
+	RetryInterval time.Duration
+	// NonceAccount is durable nonce account of the signer, single payouts are signed with its nonce instead of
+	// recent blockhash, so they don't expire until the nonce advances, nil disables durable nonces
+	NonceAccount *common.PublicKey
+}
+
 type solanaChain struct {
 	client      *client.Client
 	signer      types.Account
 	id          string
 	name        string
 	kind        string
-	decimals    float64
+	decimals    uint8
 	nativeToken string
 	rpc         string
+	opts        SolanaOptions
+
+	// unconfirmed are hashes of transactions being resent, they can still land even if network doesn't know them yet
+	unconfirmed map[string]struct{}
+	// nonceLease identifies transaction the durable nonce is leased to until the nonce advances, 0 if it's free.
+	// Only one transaction of a nonce can land, so other payouts are signed with recent blockhash meanwhile.
+	nonceLease    uint64
+	nonceLeasedAt time.Time
+	nonceLeases   uint64
+	mu            sync.Mutex
+
+	// refilling is set while refill airdrop to faucet wallet is awaited
+	refilling bool
+	refillMu  sync.Mutex
 }
 
-func NewSolanaChain(client *client.Client, signer types.Account, id, nativeToken, rpc string, decimals float64) Chain {
+func NewSolanaChain(client *client.Client, signer types.Account, id, nativeToken, rpc string, decimals uint8, opts SolanaOptions) Chain {
 	return &solanaChain{
 		client:      client,
 		signer:      signer,
@@ -32,6 +106,8 @@ func NewSolanaChain(client *client.Client, signer types.Account, id, nativeToken
 		decimals:    decimals,
 		nativeToken: nativeToken,
 		rpc:         rpc,
+		opts:        opts,
+		unconfirmed: make(map[string]struct{}),
 	}
 }
 
@@ -51,47 +127,416 @@ func (c *solanaChain) NativeToken() string {
 	return c.nativeToken
 }
 
-func (c *solanaChain) Decimals() float64 {
+func (c *solanaChain) Decimals() uint8 {
 	return c.decimals
 }
 
 func (c *solanaChain) GetBalance(address string, _ *string) (balance *big.Int, err error) {
 	bal, err := c.client.GetBalance(context.TODO(), address)
 	if err != nil {
-		return
+		return nil, classifyError(err, solanaErrorRules)
 	}
 	balance = big.NewInt(int64(bal))
 	return
 }
 
-func (c *solanaChain) Send(to string, amount *big.Int, _ *string) (txHash string, err error) {
-	tx, err := c.buildTx(common.PublicKeyFromString(to), amount.Uint64())
+type solanaTx struct {
+	signed types.Transaction
+	// nonceAccount is set if transaction is signed with durable nonce of the account
+	nonceAccount *common.PublicKey
+	// nonceLease is the lease of the durable nonce released once the transaction is done with
+	nonceLease uint64
+}
+
+func (t solanaTx) Hash() string {
+	return base58.Encode(t.signed.Signatures[0])
+}
+
+// solanaAirdropHashPrefix marks placeholder hash of airdrop payout reserved before the airdrop is requested
+const solanaAirdropHashPrefix = "airdrop:"
+
+// solanaAirdrop is airdrop to the receiver requested from the cluster by Broadcast,
+// its signature is known only then, so payout is reserved with a placeholder
+type solanaAirdrop struct {
+	placeholder string
+	to          string
+	amount      *big.Int
+	signature   string
+}
+
+func (t *solanaAirdrop) Hash() string {
+	return t.placeholder
+}
+
+func (t *solanaAirdrop) NetworkHash() string {
+	return t.signature
+}
+
+// Airdrops tells whether payouts don't depend on faucet wallet balance, as they are airdropped or it is refilled
+func (c *solanaChain) Airdrops() bool {
+	return c.opts.Strategy != SolanaStrategyTransfer
+}
+
+func (c *solanaChain) Prepare(to string, amount *big.Int, _ *string) (Tx, error) {
+	switch c.opts.Strategy {
+	case SolanaStrategyAirdrop:
+		return c.airdrop(to, amount)
+	case SolanaStrategyAirdropThenTransfer:
+		if err := c.refill(amount); err != nil {
+			return nil, err
+		}
+	}
+	return c.prepareTransfer(to, amount)
+}
+
+// prepareTransfer signs transfer with durable nonce if nonce account is configured and the nonce is not leased
+// to another payout, otherwise recent blockhash is used. Transactions signed with the same nonce pay at most once together,
+// so the nonce is leased until it advances.
+func (c *solanaChain) prepareTransfer(to string, amount *big.Int) (Tx, error) {
+	transfers := []Transfer{{To: to, Amount: amount}}
+	lease, leased := c.leaseNonce()
+	if !leased {
+		blockhash, err := c.client.GetLatestBlockhash(context.TODO())
+		if err != nil {
+			return nil, classifyError(err, solanaErrorRules)
+		}
+
+		tx, err := c.buildTx(blockhash.Blockhash, nil, transfers)
+		if err != nil {
+			return nil, classifyError(err, solanaErrorRules)
+		}
+		return solanaTx{signed: tx}, nil
+	}
+
+	nonce, err := c.client.GetNonceFromNonceAccount(context.TODO(), c.opts.NonceAccount.ToBase58())
+	if err != nil {
+		c.releaseNonce(lease)
+		return nil, classifyError(err, solanaErrorRules)
+	}
+
+	tx, err := c.buildTx(nonce, c.opts.NonceAccount, transfers)
+	if err != nil {
+		c.releaseNonce(lease)
+		return nil, classifyError(err, solanaErrorRules)
+	}
+	return solanaTx{signed: tx, nonceAccount: c.opts.NonceAccount, nonceLease: lease}, nil
+}
+
+// leaseNonce leases durable nonce to a transaction, lease of transaction that was never broadcast or stopped being resent
+// expires after solanaRebroadcastTimeout
+func (c *solanaChain) leaseNonce() (uint64, bool) {
+	if c.opts.NonceAccount == nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nonceLease != 0 && time.Since(c.nonceLeasedAt) < solanaRebroadcastTimeout {
+		return 0, false
+	}
+	c.nonceLeases++
+	c.nonceLease = c.nonceLeases
+	c.nonceLeasedAt = time.Now()
+	return c.nonceLease, true
+}
+
+// releaseNonce frees the nonce unless the lease has already expired and the nonce is leased to another transaction
+func (c *solanaChain) releaseNonce(lease uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.nonceLease == lease {
+		c.nonceLease = 0
+	}
+}
+
+// PrepareBatch packs transfers into as few transactions as the packet size limit allows.
+// Recent blockhash is used even if nonce account is configured, as a nonce pays a single transaction.
+func (c *solanaChain) PrepareBatch(transfers []Transfer) ([]Tx, error) {
+	switch c.opts.Strategy {
+	case SolanaStrategyAirdrop:
+		result := make([]Tx, 0, len(transfers))
+		for _, transfer := range transfers {
+			tx, err := c.airdrop(transfer.To, transfer.Amount)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, tx)
+		}
+		return result, nil
+	case SolanaStrategyAirdropThenTransfer:
+		total := big.NewInt(0)
+		for _, transfer := range transfers {
+			total.Add(total, transfer.Amount)
+		}
+		if err := c.refill(total); err != nil {
+			return nil, err
+		}
+	}
+
+	blockhash, err := c.client.GetLatestBlockhash(context.TODO())
 	if err != nil {
-		return
+		return nil, classifyError(err, solanaErrorRules)
+	}
+
+	result := make([]Tx, len(transfers))
+	for start := 0; start < len(transfers); {
+		tx, packed, err := c.packTransfers(blockhash.Blockhash, transfers[start:])
+		if err != nil {
+			return nil, classifyError(err, solanaErrorRules)
+		}
+		for i := start; i < start+packed; i++ {
+			result[i] = tx
+		}
+		start += packed
+	}
+	return result, nil
+}
+
+// packTransfers builds transaction of the longest prefix of transfers fitting into it, the prefix length is returned
+func (c *solanaChain) packTransfers(blockhash string, transfers []Transfer) (Tx, int, error) {
+	var packed types.Transaction
+	for n := 1; n <= len(transfers); n++ {
+		tx, err := c.buildTx(blockhash, nil, transfers[:n])
+		if err != nil {
+			return nil, 0, err
+		}
+
+		raw, err := tx.Serialize()
+		if err != nil {
+			return nil, 0, err
+		}
+		if len(raw) > solanaMaxTxSize {
+			if n == 1 {
+				return nil, 0, errors.New("transfer does not fit into transaction")
+			}
+			return solanaTx{signed: packed}, n - 1, nil
+		}
+		packed = tx
+	}
+	return solanaTx{signed: packed}, len(transfers), nil
+}
+
+// airdrop prepares airdrop of the amount to the address, it is requested on broadcast
+func (c *solanaChain) airdrop(to string, amount *big.Int) (Tx, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	return &solanaAirdrop{
+		placeholder: solanaAirdropHashPrefix + hex.EncodeToString(id),
+		to:          to,
+		amount:      amount,
+	}, nil
+}
+
+// requestAirdrop requests the cluster to airdrop the amount to the address, signature of the airdrop is returned
+func (c *solanaChain) requestAirdrop(to string, amount *big.Int) (string, error) {
+	signature, err := c.client.RequestAirdrop(context.TODO(), to, amount.Uint64())
+	if err != nil {
+		return "", classifyError(err, solanaErrorRules)
+	}
+	return signature, nil
+}
+
+// refill starts airdrop of the amount to faucet wallet if it can't cover it. Payouts fail with ErrRefilling
+// until the airdrop is confirmed, so requests are not held and only one refill is made at a time.
+func (c *solanaChain) refill(amount *big.Int) error {
+	signerAddress := c.signer.PublicKey.ToBase58()
+	balance, err := c.GetBalance(signerAddress, nil)
+	if err != nil {
+		return err
+	}
+	if balance.Cmp(new(big.Int).Add(amount, big.NewInt(solanaFeeReserve))) > 0 {
+		return nil
+	}
+
+	c.refillMu.Lock()
+	defer c.refillMu.Unlock()
+	if c.refilling {
+		return &Error{Kind: ErrInsufficientFunds, Err: ErrRefilling}
+	}
+
+	signature, err := c.requestAirdrop(signerAddress, amount)
+	if err != nil {
+		return err
+	}
+	c.refilling = true
+	go c.awaitRefill(signature)
+	return &Error{Kind: ErrInsufficientFunds, Err: ErrRefilling}
+}
+
+// awaitRefill polls refill airdrop until it is done or solanaAirdropTimeout passes
+func (c *solanaChain) awaitRefill(signature string) {
+	defer func() {
+		c.refillMu.Lock()
+		c.refilling = false
+		c.refillMu.Unlock()
+	}()
+
+	for deadline := time.Now().Add(solanaAirdropTimeout); time.Now().Before(deadline); {
+		time.Sleep(solanaAirdropPollInterval)
+		status, err := c.TxStatus(signature)
+		if err == nil && (status == TxStatusSuccess || status == TxStatusFailed) {
+			return
+		}
+	}
+}
+
+func (c *solanaChain) Broadcast(tx Tx) error {
+	if airdrop, ok := tx.(*solanaAirdrop); ok {
+		signature, err := c.requestAirdrop(airdrop.to, airdrop.amount)
+		if err != nil {
+			return err
+		}
+		airdrop.signature = signature
+		return nil
+	}
+
+	solTx := tx.(solanaTx)
+	_, err := c.client.SendTransaction(context.TODO(), solTx.signed)
+	if err = acceptKnown(classifyError(err, solanaErrorRules)); err != nil {
+		if solTx.nonceAccount != nil && IsRejected(err) {
+			// transaction that may have been delivered keeps the nonce until the lease expires
+			c.releaseNonce(solTx.nonceLease)
+		}
+		return err
+	}
+
+	if c.opts.RetryInterval > 0 {
+		go c.rebroadcast(solTx)
+	}
+	return nil
+}
+
+// rebroadcast resends transaction until it is confirmed or its blockhash expires, as rpc nodes drop transactions
+// during congestion. Resending is safe, since network executes the same signed transaction once.
+// It gives up after solanaRebroadcastTimeout or solanaRebroadcastMaxFailures rpc failures in a row.
+func (c *solanaChain) rebroadcast(tx solanaTx) {
+	hash := tx.Hash()
+	c.mu.Lock()
+	c.unconfirmed[hash] = struct{}{}
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.unconfirmed, hash)
+		c.mu.Unlock()
+		if tx.nonceAccount != nil {
+			c.releaseNonce(tx.nonceLease)
+		}
+	}()
+
+	ticker := time.NewTicker(c.opts.RetryInterval)
+	defer ticker.Stop()
+	deadline := time.Now().Add(solanaRebroadcastTimeout)
+	failures := 0
+	for range ticker.C {
+		if time.Now().After(deadline) || failures >= solanaRebroadcastMaxFailures {
+			return
+		}
+
+		status, statusErr := c.TxStatus(hash)
+		if statusErr == nil && (status == TxStatusSuccess || status == TxStatusFailed) {
+			return
+		}
+
+		expired, expiredErr := c.isExpired(tx)
+		if expiredErr == nil && expired {
+			return
+		}
+
+		_, sendErr := c.client.SendTransactionWithConfig(context.TODO(), tx.signed, client.SendTransactionConfig{SkipPreflight: true})
+		if statusErr != nil || expiredErr != nil || sendErr != nil {
+			failures++
+			continue
+		}
+		failures = 0
 	}
-	txHash, err = c.client.SendTransaction(context.TODO(), tx)
-	return
 }
 
-func (c *solanaChain) buildTx(receiver common.PublicKey, amount uint64) (tx types.Transaction, err error) {
-	response, err := c.client.GetLatestBlockhash(context.TODO())
+// isExpired tells whether transaction can't land anymore, as its blockhash is too old or its durable nonce has advanced
+func (c *solanaChain) isExpired(tx solanaTx) (bool, error) {
+	if tx.nonceAccount == nil {
+		valid, err := c.client.IsBlockhashValid(context.TODO(), tx.signed.Message.RecentBlockHash)
+		return !valid, err
+	}
+
+	nonce, err := c.client.GetNonceFromNonceAccount(context.TODO(), tx.nonceAccount.ToBase58())
+	return nonce != tx.signed.Message.RecentBlockHash, err
+}
+
+// TxStatus searches transaction history as well, since recent status cache of rpc node keeps only last slots
+// and landed transactions would be reported not found otherwise
+func (c *solanaChain) TxStatus(txHash string) (TxStatus, error) {
+	if strings.HasPrefix(txHash, solanaAirdropHashPrefix) {
+		// airdrop was not requested or its signature was not recorded
+		return TxStatusNotFound, nil
+	}
+
+	status, err := c.client.GetSignatureStatusWithConfig(context.TODO(), txHash, rpc.GetSignatureStatusesConfig{
+		SearchTransactionHistory: true,
+	})
 	if err != nil {
-		return
+		return "", err
+	}
+
+	switch {
+	case status == nil:
+		if c.isUnconfirmed(txHash) {
+			return TxStatusPending, nil
+		}
+		return TxStatusNotFound, nil
+	case status.Err != nil:
+		return TxStatusFailed, nil
+	case status.ConfirmationStatus != nil && *status.ConfirmationStatus != rpc.CommitmentProcessed:
+		return TxStatusSuccess, nil
+	default:
+		return TxStatusPending, nil
+	}
+}
+
+func (c *solanaChain) isUnconfirmed(txHash string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.unconfirmed[txHash]
+	return ok
+}
+
+// buildTx pays transfers by a single transaction, compute budget is requested if priority fee is set.
+// If nonce account is passed, blockhash is its durable nonce, which is advanced by the transaction.
+func (c *solanaChain) buildTx(blockhash string, nonceAccount *common.PublicKey, transfers []Transfer) (tx types.Transaction, err error) {
+	var instructions []types.Instruction
+	if nonceAccount != nil {
+		// nonce advance has to be the first instruction
+		instructions = append(instructions, sysprog.AdvanceNonceAccount(sysprog.AdvanceNonceAccountParam{
+			Nonce: *nonceAccount,
+			Auth:  c.signer.PublicKey,
+		}))
+	}
+	if c.opts.PriorityFee > 0 {
+		instructions = append(instructions,
+			compute_budget.SetComputeUnitLimit(compute_budget.SetComputeUnitLimitParam{
+				Units: uint32(solanaComputeBudgetUnits + solanaTransferComputeUnits*len(transfers)),
+			}),
+			compute_budget.SetComputeUnitPrice(compute_budget.SetComputeUnitPriceParam{
+				MicroLamports: c.opts.PriorityFee,
+			}),
+		)
+	}
+	for _, transfer := range transfers {
+		instructions = append(instructions, sysprog.Transfer(
+			sysprog.TransferParam{
+				From:   c.signer.PublicKey,                      // public key of the transaction sender
+				To:     common.PublicKeyFromString(transfer.To), // wallet address of the transaction receiver
+				Amount: transfer.Amount.Uint64(),                // transaction amount
+			},
+		))
 	}
 
 	message := types.NewMessage(
 		types.NewMessageParam{
-			FeePayer: c.signer.PublicKey, // public key of the transaction signer
-			Instructions: []types.Instruction{
-				sysprog.Transfer(
-					sysprog.TransferParam{
-						From:   c.signer.PublicKey, // public key of the transaction sender
-						To:     receiver,           // wallet address of the transaction receiver
-						Amount: amount,             // transaction amount
-					},
-				),
-			},
-			RecentBlockhash: response.Blockhash, // recent block hash
+			FeePayer:        c.signer.PublicKey, // public key of the transaction signer
+			Instructions:    instructions,
+			RecentBlockhash: blockhash, // recent block hash
 		},
 	)
 
@@ -105,6 +550,23 @@ func (c *solanaChain) buildTx(receiver common.PublicKey, amount uint64) (tx type
 	return
 }
 
+// VerifySignature checks base58 ed25519 signature of the message, address is the public key itself
+func (c *solanaChain) VerifySignature(address, message, signature string, _ *string) error {
+	pubKey, err := base58.Decode(address)
+	if err != nil || len(pubKey) != ed25519.PublicKeySize {
+		return ErrInvalidSignature
+	}
+	sig, err := base58.Decode(signature)
+	if err != nil || len(sig) != ed25519.SignatureSize {
+		return ErrInvalidSignature
+	}
+
+	if !ed25519.Verify(pubKey, []byte(message), sig) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
 func ValidateSolanaAddress(value interface{}) error {
 	err := validation.Validate(value.(string), validation.Length(32, 44))
 	if err != nil {
@@ -0,0 +1,31 @@
+package pg
+
+import (
+	"strings"
+	"time"
+)
+
+// Address is receiver address the user has proven ownership of, an address is bound to a single user
+type Address struct {
+	ChainType  string    `db:"chain_type"`
+	Address    string    `db:"address"`
+	UserId     string    `db:"user_id"`
+	VerifiedAt time.Time `db:"verified_at"`
+}
+
+func NewAddress(chainType, address, userId string) Address {
+	return Address{
+		ChainType: chainType,
+		Address:   NormalizeAddress(chainType, address),
+		UserId:    userId,
+	}
+}
+
+// NormalizeAddress lowercases evm addresses, so they match regardless of checksum case,
+// solana addresses are case-sensitive base58 and near accounts are lowercase already
+func NormalizeAddress(chainType, address string) string {
+	if chainType == "evm" {
+		return strings.ToLower(address)
+	}
+	return address
+}
@@ -1,24 +1,33 @@
 package pg
 
+import (
+	"faucet-svc/internal/types"
+	"math/big"
+	"strings"
+	"time"
+)
+
 type Balance struct {
-	ID           uint64  `db:"id"`
-	UserId       string  `db:"user_id"`
-	ChainId      string  `db:"chain_id"`
-	ChainType    string  `db:"chain_type"`
-	TokenAddress string  `db:"token_address"`
-	Amount       float64 `db:"amount"`
+	ID           uint64       `db:"id"`
+	UserId       string       `db:"user_id"`
+	ChainId      string       `db:"chain_id"`
+	ChainType    string       `db:"chain_type"`
+	TokenAddress string       `db:"token_address"`
+	Amount       types.Amount `db:"amount"`
+	LastClaimAt  time.Time    `db:"last_claim_at"`
 }
 
-func NewBalance(userId, chainId, chainType string, amount float64, tokenAddress *string) Balance {
+// NewBalance - amount is expected in base units of the chain or token
+func NewBalance(userId, chainId, chainType string, amount *big.Int, tokenAddress *string) Balance {
 	tknAddr := ""
 	if tokenAddress != nil {
-		tknAddr = *tokenAddress
+		tknAddr = strings.ToLower(*tokenAddress)
 	}
 	return Balance{
 		UserId:       userId,
 		ChainId:      chainId,
 		ChainType:    chainType,
 		TokenAddress: tknAddr,
-		Amount:       amount,
+		Amount:       types.NewAmount(amount),
 	}
 }
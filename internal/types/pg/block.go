@@ -0,0 +1,39 @@
+package pg
+
+import (
+	"time"
+)
+
+type BlockKind string
+
+const (
+	BlockKindUser    BlockKind = "user"
+	BlockKindAddress BlockKind = "address"
+)
+
+// Block forbids payouts to the user or to the receiver address
+type Block struct {
+	Kind      BlockKind `db:"kind"`
+	Value     string    `db:"value"`
+	Reason    string    `db:"reason"`
+	CreatedBy string    `db:"created_by"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// NewBlock - addresses are stored normalized for the chain type, so evm addresses match regardless of checksum case
+func NewBlock(kind BlockKind, chainType, value, reason, createdBy string) Block {
+	return Block{
+		Kind:      kind,
+		Value:     NormalizeBlockValue(kind, chainType, value),
+		Reason:    reason,
+		CreatedBy: createdBy,
+	}
+}
+
+// NormalizeBlockValue normalizes address of the chain type, user ids are kept as is
+func NormalizeBlockValue(kind BlockKind, chainType, value string) string {
+	if kind == BlockKindAddress {
+		return NormalizeAddress(chainType, value)
+	}
+	return value
+}
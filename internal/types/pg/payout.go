@@ -0,0 +1,80 @@
+package pg
+
+import (
+	"faucet-svc/internal/types"
+	"math/big"
+	"strings"
+	"time"
+)
+
+type PayoutStatus string
+
+const (
+	// PayoutStatusPending - payout is reserved, transaction may or may not be broadcast
+	PayoutStatusPending PayoutStatus = "pending"
+	PayoutStatusSent    PayoutStatus = "sent"
+	// PayoutStatusFailed - transaction was not delivered, reserved amount is returned to user quota
+	PayoutStatusFailed PayoutStatus = "failed"
+)
+
+type Payout struct {
+	ID           uint64       `db:"id"`
+	UserId       string       `db:"user_id"`
+	ChainType    string       `db:"chain_type"`
+	ChainId      string       `db:"chain_id"`
+	TokenAddress string       `db:"token_address"`
+	Receiver     string       `db:"receiver"`
+	Amount       types.Amount `db:"amount"`
+	TxHash       string       `db:"tx_hash"`
+	Status       PayoutStatus `db:"status"`
+	// IdempotencyKey is client provided key, repeated requests with it return this payout
+	IdempotencyKey *string `db:"idempotency_key"`
+	// RequestHash is a fingerprint of the request body the idempotency key was used with
+	RequestHash *string `db:"request_hash"`
+	// RefundOf is id of the failed payout this one re-sends
+	RefundOf *uint64 `db:"refund_of"`
+	// IP is client ip the payout was requested from, nil for payouts made by admins
+	IP        *string   `db:"ip"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func NewPayout(userId, chainId, chainType, receiver, txHash string, amount *big.Int, tokenAddress *string) Payout {
+	tknAddr := ""
+	if tokenAddress != nil {
+		tknAddr = strings.ToLower(*tokenAddress)
+	}
+	return Payout{
+		UserId:       userId,
+		ChainType:    chainType,
+		ChainId:      chainId,
+		TokenAddress: tknAddr,
+		Receiver:     receiver,
+		Amount:       types.NewAmount(amount),
+		TxHash:       txHash,
+		Status:       PayoutStatusPending,
+	}
+}
+
+// TokenAddressPtr returns token address or nil for native coin payouts
+func (p Payout) TokenAddressPtr() *string {
+	if p.TokenAddress == "" {
+		return nil
+	}
+	tknAddr := p.TokenAddress
+	return &tknAddr
+}
+
+// Balance returns claimed balance increment the payout accounts for
+func (p Payout) Balance() Balance {
+	return NewBalance(p.UserId, p.ChainId, p.ChainType, &p.Amount.Int, p.TokenAddressPtr())
+}
+
+// PayoutTotals aggregates payouts matching some filter
+type PayoutTotals struct {
+	Count uint64 `db:"count"`
+	// Users is the number of distinct users made the payouts
+	Users         uint64       `db:"users"`
+	Amount        types.Amount `db:"amount"`
+	LastCreatedAt *time.Time   `db:"last_created_at"`
+}
@@ -0,0 +1,89 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+	"time"
+)
+
+// Quota limits how much a single user can claim on chain or token
+type Quota struct {
+	// Role is user role the quota applies to, empty means any role
+	Role         string
+	ChainType    string
+	ChainID      string
+	TokenAddress string
+	// MaxAmount is total amount in base units user can receive, nil means unlimited
+	MaxAmount *big.Int
+	// Cooldown is minimal period between two claims of a user
+	Cooldown time.Duration
+}
+
+// Remaining returns amount user still can claim, nil means unlimited
+func (q Quota) Remaining(claimed *big.Int) *big.Int {
+	if q.MaxAmount == nil {
+		return nil
+	}
+
+	remaining := new(big.Int).Sub(q.MaxAmount, claimed)
+	if remaining.Sign() < 0 {
+		remaining.SetInt64(0)
+	}
+	return remaining
+}
+
+// NextClaimAt returns time of the next allowed claim, nil means user can claim right now
+func (q Quota) NextClaimAt(lastClaimAt *time.Time, now time.Time) *time.Time {
+	if lastClaimAt == nil || q.Cooldown == 0 {
+		return nil
+	}
+
+	next := lastClaimAt.Add(q.Cooldown)
+	if !next.After(now) {
+		return nil
+	}
+	return &next
+}
+
+type Quotas []Quota
+
+// Get returns the most specific quota: role specific quotas take precedence,
+// then chain level, then chain type level. Amounts are in base units of the currency,
+// so quotas without token address apply to native payouts only and token quotas have to be explicit
+func (quotas Quotas) Get(role, chainType, chainId string, tokenAddress *string) Quota {
+	tknAddr := ""
+	if tokenAddress != nil {
+		tknAddr = strings.ToLower(*tokenAddress)
+	}
+
+	result := Quota{Role: role, ChainType: chainType, ChainID: chainId, TokenAddress: tknAddr}
+	bestScore := -1
+	for _, quota := range quotas {
+		if quota.ChainType != chainType {
+			continue
+		}
+		if quota.Role != "" && quota.Role != role {
+			continue
+		}
+		if quota.ChainID != "" && quota.ChainID != chainId {
+			continue
+		}
+		if quota.TokenAddress != tknAddr {
+			continue
+		}
+
+		score := 0
+		if quota.ChainID != "" {
+			score++
+		}
+		if quota.Role != "" {
+			score += 2
+		}
+		if score > bestScore {
+			bestScore = score
+			result.MaxAmount = quota.MaxAmount
+			result.Cooldown = quota.Cooldown
+		}
+	}
+	return result
+}
@@ -0,0 +1,53 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+	"time"
+)
+
+func TestQuotasGet(t *testing.T) {
+	token := "0xAbC0000000000000000000000000000000000001"
+	quotas := Quotas{
+		{ChainType: "evm", MaxAmount: big.NewInt(1)},
+		{ChainType: "evm", ChainID: "5", MaxAmount: big.NewInt(2), Cooldown: time.Hour},
+		{Role: "tester", ChainType: "evm", MaxAmount: big.NewInt(3)},
+		{Role: "tester", ChainType: "evm", ChainID: "5", MaxAmount: big.NewInt(4)},
+		{Role: "vip", ChainType: "evm", Cooldown: time.Minute},
+		{ChainType: "evm", TokenAddress: "0xabc0000000000000000000000000000000000001", MaxAmount: big.NewInt(5)},
+		{Role: "tester", ChainType: "evm", TokenAddress: "0xabc0000000000000000000000000000000000001", MaxAmount: big.NewInt(6)},
+		{ChainType: "solana", ChainID: "devnet", MaxAmount: big.NewInt(7)},
+	}
+
+	cases := []struct {
+		name         string
+		role         string
+		chainType    string
+		chainId      string
+		tokenAddress *string
+		wantMax      *big.Int
+		wantCooldown time.Duration
+	}{
+		{"chain type", "", "evm", "1", nil, big.NewInt(1), 0},
+		{"chain over chain type", "", "evm", "5", nil, big.NewInt(2), time.Hour},
+		{"role over chain", "tester", "evm", "1", nil, big.NewInt(3), 0},
+		{"role and chain", "tester", "evm", "5", nil, big.NewInt(4), 0},
+		{"role chain type over chain", "vip", "evm", "5", nil, nil, time.Minute},
+		{"unknown role", "guest", "evm", "5", nil, big.NewInt(2), time.Hour},
+		{"token", "", "evm", "5", &token, big.NewInt(5), 0},
+		{"token of role", "tester", "evm", "5", &token, big.NewInt(6), 0},
+		{"native quota not applied to token", "", "evm", "5", strPtr("0xdef0000000000000000000000000000000000002"), nil, 0},
+		{"other chain of the type", "", "solana", "mainnet", nil, nil, 0},
+		{"other chain type", "tester", "near", "testnet", nil, nil, 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := quotas.Get(tc.role, tc.chainType, tc.chainId, tc.tokenAddress)
+			if !equalInts(got.MaxAmount, tc.wantMax) || got.Cooldown != tc.wantCooldown {
+				t.Errorf("Get(%s, %s, %s) = max %v, cooldown %s, want max %v, cooldown %s",
+					tc.role, tc.chainType, tc.chainId, got.MaxAmount, got.Cooldown, tc.wantMax, tc.wantCooldown)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package types
+
+import (
+	"math/big"
+	"strings"
+)
+
+// RecipientBalance restricts payouts to receivers who already hold enough on chain or token
+type RecipientBalance struct {
+	ChainType    string
+	ChainID      string
+	TokenAddress string
+	// MaxBalance is receiver balance in base units payouts are refused at, nil means unlimited
+	MaxBalance *big.Int
+	// TopUpTo caps payout so receiver balance reaches it at most, nil means requested amount is sent
+	TopUpTo *big.Int
+}
+
+// Amount returns amount to be sent to receiver holding the balance, nil if receiver is not eligible
+func (p RecipientBalance) Amount(balance, requested *big.Int) *big.Int {
+	if p.MaxBalance != nil && balance.Cmp(p.MaxBalance) >= 0 {
+		return nil
+	}
+	if p.TopUpTo == nil {
+		return requested
+	}
+
+	missing := new(big.Int).Sub(p.TopUpTo, balance)
+	if missing.Sign() <= 0 {
+		return nil
+	}
+	if missing.Cmp(requested) < 0 {
+		return missing
+	}
+	return requested
+}
+
+// IsEmpty is true if no restriction applies, so receiver balance doesn't have to be fetched
+func (p RecipientBalance) IsEmpty() bool {
+	return p.MaxBalance == nil && p.TopUpTo == nil
+}
+
+type RecipientBalances []RecipientBalance
+
+// Get returns the most specific policy: chain level, then chain type level. Balances are in base units of the currency,
+// so policies without token address apply to native payouts only
+func (policies RecipientBalances) Get(chainType, chainId string, tokenAddress *string) RecipientBalance {
+	tknAddr := ""
+	if tokenAddress != nil {
+		tknAddr = strings.ToLower(*tokenAddress)
+	}
+
+	result := RecipientBalance{ChainType: chainType, ChainID: chainId, TokenAddress: tknAddr}
+	bestScore := -1
+	for _, policy := range policies {
+		if policy.ChainType != chainType {
+			continue
+		}
+		if policy.ChainID != "" && policy.ChainID != chainId {
+			continue
+		}
+		if policy.TokenAddress != tknAddr {
+			continue
+		}
+
+		score := 0
+		if policy.ChainID != "" {
+			score++
+		}
+		if score > bestScore {
+			bestScore = score
+			result.MaxBalance = policy.MaxBalance
+			result.TopUpTo = policy.TopUpTo
+		}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestRecipientBalancesGet(t *testing.T) {
+	token := "0xAbC0000000000000000000000000000000000001"
+	policies := RecipientBalances{
+		{ChainType: "evm", MaxBalance: big.NewInt(1)},
+		{ChainType: "evm", ChainID: "5", MaxBalance: big.NewInt(2)},
+		{ChainType: "evm", TokenAddress: "0xabc0000000000000000000000000000000000001", MaxBalance: big.NewInt(3)},
+		{ChainType: "evm", ChainID: "5", TokenAddress: "0xabc0000000000000000000000000000000000001", TopUpTo: big.NewInt(4)},
+		{ChainType: "solana", ChainID: "devnet", MaxBalance: big.NewInt(5)},
+	}
+
+	cases := []struct {
+		name         string
+		chainType    string
+		chainId      string
+		tokenAddress *string
+		wantMax      *big.Int
+		wantTopUpTo  *big.Int
+	}{
+		{"chain over chain type", "evm", "5", nil, big.NewInt(2), nil},
+		{"chain type", "evm", "1", nil, big.NewInt(1), nil},
+		{"token on chain", "evm", "5", &token, nil, big.NewInt(4)},
+		{"token on chain type", "evm", "1", &token, big.NewInt(3), nil},
+		{"native policy not applied to other token", "evm", "1", strPtr("0xdef0000000000000000000000000000000000002"), nil, nil},
+		{"other chain type", "near", "testnet", nil, nil, nil},
+		{"other chain of the type", "solana", "mainnet", nil, nil, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := policies.Get(tc.chainType, tc.chainId, tc.tokenAddress)
+			if !equalInts(got.MaxBalance, tc.wantMax) || !equalInts(got.TopUpTo, tc.wantTopUpTo) {
+				t.Errorf("Get(%s, %s) = max %v, top up to %v, want max %v, top up to %v",
+					tc.chainType, tc.chainId, got.MaxBalance, got.TopUpTo, tc.wantMax, tc.wantTopUpTo)
+			}
+			if got.ChainType != tc.chainType || got.ChainID != tc.chainId {
+				t.Errorf("Get(%s, %s) returned policy of %s %s", tc.chainType, tc.chainId, got.ChainType, got.ChainID)
+			}
+		})
+	}
+}
+
+func TestRecipientBalanceAmount(t *testing.T) {
+	cases := []struct {
+		name      string
+		policy    RecipientBalance
+		balance   int64
+		requested int64
+		want      *big.Int
+	}{
+		{"no restriction", RecipientBalance{}, 1000, 100, big.NewInt(100)},
+		{"below max balance", RecipientBalance{MaxBalance: big.NewInt(500)}, 499, 100, big.NewInt(100)},
+		{"at max balance", RecipientBalance{MaxBalance: big.NewInt(500)}, 500, 100, nil},
+		{"above max balance", RecipientBalance{MaxBalance: big.NewInt(500)}, 501, 100, nil},
+		{"top up capped", RecipientBalance{TopUpTo: big.NewInt(500)}, 450, 100, big.NewInt(50)},
+		{"top up not capped", RecipientBalance{TopUpTo: big.NewInt(500)}, 100, 100, big.NewInt(100)},
+		{"top up exactly requested", RecipientBalance{TopUpTo: big.NewInt(500)}, 400, 100, big.NewInt(100)},
+		{"topped up already", RecipientBalance{TopUpTo: big.NewInt(500)}, 500, 100, nil},
+		{"max balance before top up", RecipientBalance{MaxBalance: big.NewInt(300), TopUpTo: big.NewInt(500)}, 300, 100, nil},
+		{"both below", RecipientBalance{MaxBalance: big.NewInt(300), TopUpTo: big.NewInt(500)}, 250, 100, big.NewInt(100)},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.policy.Amount(big.NewInt(tc.balance), big.NewInt(tc.requested))
+			if !equalInts(got, tc.want) {
+				t.Errorf("Amount(%d, %d) = %v, want %v", tc.balance, tc.requested, got, tc.want)
+			}
+		})
+	}
+}
+
+func strPtr(value string) *string {
+	return &value
+}
+
+func equalInts(a, b *big.Int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
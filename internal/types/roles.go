@@ -0,0 +1,32 @@
+package types
+
+const (
+	RoleUser    = "user"
+	RoleTrusted = "trusted"
+	RoleAdmin   = "admin"
+	// RoleAnonymous is given to captcha verified requests without account, it is never taken from auth provider
+	RoleAnonymous = "anonymous"
+)
+
+// rolePriority orders roles from the least to the most privileged
+var rolePriority = map[string]int{
+	RoleUser:    0,
+	RoleTrusted: 1,
+	RoleAdmin:   2,
+}
+
+func IsKnownRole(role string) bool {
+	_, ok := rolePriority[role]
+	return ok || role == RoleAnonymous
+}
+
+// EffectiveRole returns the most privileged known role, unknown roles are ignored, default is user
+func EffectiveRole(roles []string) string {
+	result := RoleUser
+	for _, role := range roles {
+		if priority, ok := rolePriority[role]; ok && priority > rolePriority[result] {
+			result = role
+		}
+	}
+	return result
+}
@@ -42,22 +42,26 @@ func (s *evmSigner) PrivKey() *ecdsa.PrivateKey {
 type NearSigner interface {
 	ID() string
 	KeyPair() key.KeyPair
+	// KeyPairs are all access keys of the account transactions can be signed with, the first one is KeyPair
+	KeyPairs() []key.KeyPair
 }
 
 type nearSigner struct {
-	id      string
-	keyPair key.KeyPair
+	id       string
+	keyPairs []key.KeyPair
 }
 
-func NewNearSigner(id, privKey string) (NearSigner, error) {
-	keyPair, err := key.NewBase58KeyPair(privKey)
-	if err != nil {
-		return nil, err
-	}
-
+// NewNearSigner creates signer of the account, extra keys are other full access keys of the same account
+func NewNearSigner(id, privKey string, extraKeys ...string) (NearSigner, error) {
 	signer := nearSigner{
-		id:      id,
-		keyPair: keyPair,
+		id: id,
+	}
+	for _, raw := range append([]string{privKey}, extraKeys...) {
+		keyPair, err := key.NewBase58KeyPair(raw)
+		if err != nil {
+			return nil, err
+		}
+		signer.keyPairs = append(signer.keyPairs, keyPair)
 	}
 
 	return &signer, nil
@@ -68,5 +72,9 @@ func (s *nearSigner) ID() string {
 }
 
 func (s *nearSigner) KeyPair() key.KeyPair {
-	return s.keyPair
+	return s.keyPairs[0]
+}
+
+func (s *nearSigner) KeyPairs() []key.KeyPair {
+	return s.keyPairs
 }
@@ -1,12 +1,14 @@
 package types
 
+import "strings"
+
 type EvmToken interface {
 	Name() string
 	Symbol() string
 	Address() string
 	Kind() string
 	Chains() []string
-	Decimals() float64
+	Decimals() uint8
 }
 
 type evmToken struct {
@@ -15,10 +17,10 @@ type evmToken struct {
 	address  string
 	kind     string
 	chains   []string
-	decimals float64
+	decimals uint8
 }
 
-func NewEvmToken(name, symbol, address, kind string, chains []string, decimals float64) EvmToken {
+func NewEvmToken(name, symbol, address, kind string, chains []string, decimals uint8) EvmToken {
 	return &evmToken{
 		name:     name,
 		symbol:   symbol,
@@ -49,7 +51,7 @@ func (t *evmToken) Chains() []string {
 	return t.chains
 }
 
-func (t *evmToken) Decimals() float64 {
+func (t *evmToken) Decimals() uint8 {
 	return t.decimals
 }
 
@@ -60,6 +62,24 @@ func (tokens EvmTokens) Get(key string) (EvmToken, bool) {
 	return val, ok
 }
 
+// Find returns token configured for the chain, tokens are configured for evm chains only
+func (tokens EvmTokens) Find(chainType, chainId, address string) (EvmToken, bool) {
+	if chainType != "evm" {
+		return nil, false
+	}
+
+	token, ok := tokens[strings.ToLower(address)]
+	if !ok {
+		return nil, false
+	}
+	for _, id := range token.Chains() {
+		if id == chainId {
+			return token, true
+		}
+	}
+	return nil, false
+}
+
 func (tokens EvmTokens) Set(key string, val EvmToken) bool {
 	if _, ok := tokens[key]; ok {
 		return false
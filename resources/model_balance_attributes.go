@@ -0,0 +1,24 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type BalanceAttributes struct {
+	// total claimed amount in base units
+	Amount    string `json:"amount"`
+	ChainId   string `json:"chain_id"`
+	ChainType string `json:"chain_type"`
+	// total claimed amount in whole coins, omitted if chain or token is no longer configured
+	HumanAmount *string   `json:"human_amount,omitempty"`
+	LastClaimAt time.Time `json:"last_claim_at"`
+	// time of the next allowed claim, omitted if user can claim right now
+	NextClaimAt *time.Time `json:"next_claim_at,omitempty"`
+	// seconds until the next allowed claim
+	NextClaimIn int64 `json:"next_claim_in"`
+	// amount in base units user still can claim, omitted if unlimited
+	RemainingQuota *string `json:"remaining_quota,omitempty"`
+	TokenAddress   *string `json:"token_address,omitempty"`
+}
@@ -0,0 +1,19 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type BlockAttributes struct {
+	// chain type of the blocked address, required for address blocks to normalize it, is not returned
+	ChainType *string   `json:"chain_type,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	CreatedBy string    `json:"created_by"`
+	// user or address
+	Kind   string `json:"kind"`
+	Reason string `json:"reason"`
+	// user id or receiver address
+	Value string `json:"value"`
+}
@@ -4,8 +4,16 @@
 
 package resources
 
+import "time"
+
 type ChainAttributes struct {
-	Balance     *float64 `json:"balance,omitempty"`
-	Name        string   `json:"name"`
-	NativeToken string   `json:"native_token"`
+	Balance *string `json:"balance,omitempty"`
+	// reason why the balance could not be refreshed
+	Error       *string `json:"error,omitempty"`
+	Name        string  `json:"name"`
+	NativeToken string  `json:"native_token"`
+	// balance was not refreshed recently and may be outdated
+	Stale bool `json:"stale"`
+	// time of the last successful balance refresh
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
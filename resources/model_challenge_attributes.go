@@ -0,0 +1,17 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type ChallengeAttributes struct {
+	// hash function used for solution
+	Algorithm string `json:"algorithm"`
+	// number of leading zero bits the solution hash must have
+	Difficulty int32     `json:"difficulty"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	// signed challenge to be sent back in Pow-Challenge header
+	Token string `json:"token"`
+}
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type OwnershipNonce struct {
+	Key
+	Attributes OwnershipNonceAttributes `json:"attributes"`
+}
+type OwnershipNonceResponse struct {
+	Data     OwnershipNonce `json:"data"`
+	Included Included       `json:"included"`
+}
+
+type OwnershipNonceListResponse struct {
+	Data     []OwnershipNonce `json:"data"`
+	Included Included         `json:"included"`
+	Links    *Links           `json:"links"`
+}
+
+// MustOwnershipNonce - returns OwnershipNonce from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustOwnershipNonce(key Key) *OwnershipNonce {
+	var ownershipNonce OwnershipNonce
+	if c.tryFindEntry(key, &ownershipNonce) {
+		return &ownershipNonce
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type OwnershipNonceAttributes struct {
+	Address   string    `json:"address"`
+	ChainType string    `json:"chain_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// exact text to be signed by the address owner
+	Message string `json:"message"`
+	// nonce to be sent back with the signature
+	Nonce string `json:"nonce"`
+}
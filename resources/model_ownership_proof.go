@@ -0,0 +1,14 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type OwnershipProof struct {
+	// nonce issued for the receiver address
+	Nonce string `json:"nonce"`
+	// ed25519 public key of near access key the message is signed with, e.g. ed25519:...
+	PublicKey *string `json:"public_key,omitempty"`
+	// signature of the nonce message, hex for evm and base58 for solana and near
+	Signature string `json:"signature"`
+}
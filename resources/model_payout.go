@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type Payout struct {
+	Key
+	Attributes PayoutAttributes `json:"attributes"`
+}
+type PayoutResponse struct {
+	Data     Payout   `json:"data"`
+	Included Included `json:"included"`
+}
+
+type PayoutListResponse struct {
+	Data     []Payout `json:"data"`
+	Included Included `json:"included"`
+	Links    *Links   `json:"links"`
+}
+
+// MustPayout - returns Payout from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustPayout(key Key) *Payout {
+	var payout Payout
+	if c.tryFindEntry(key, &payout) {
+		return &payout
+	}
+	return nil
+}
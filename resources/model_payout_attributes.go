@@ -0,0 +1,25 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type PayoutAttributes struct {
+	// amount in base units
+	Amount    string    `json:"amount"`
+	ChainId   string    `json:"chain_id"`
+	ChainType string    `json:"chain_type"`
+	CreatedAt time.Time `json:"created_at"`
+	// ip the payout was requested from, omitted for payouts made by admins
+	Ip       *string `json:"ip,omitempty"`
+	Receiver string  `json:"receiver"`
+	// id of the failed payout this one re-sends
+	RefundOf     *string   `json:"refund_of,omitempty"`
+	Status       string    `json:"status"`
+	TokenAddress *string   `json:"token_address,omitempty"`
+	TxHash       string    `json:"tx_hash"`
+	UpdatedAt    time.Time `json:"updated_at"`
+	UserId       string    `json:"user_id"`
+}
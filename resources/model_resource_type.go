@@ -8,8 +8,15 @@ type ResourceType string
 
 // List of ResourceType
 const (
-	EVM    ResourceType = "evm"
-	SOLANA ResourceType = "solana"
-	NEAR   ResourceType = "near"
-	ERC20  ResourceType = "ERC20"
+	EVM             ResourceType = "evm"
+	SOLANA          ResourceType = "solana"
+	NEAR            ResourceType = "near"
+	ERC20           ResourceType = "ERC20"
+	BALANCE         ResourceType = "balance"
+	PAYOUT          ResourceType = "payout"
+	BLOCK           ResourceType = "block"
+	WALLET          ResourceType = "wallet"
+	CHALLENGE       ResourceType = "challenge"
+	OWNERSHIP_NONCE ResourceType = "ownership_nonce"
+	SEND_RESULT     ResourceType = "send_result"
 )
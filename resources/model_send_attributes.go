@@ -7,7 +7,14 @@ package resources
 import "math/big"
 
 type SendAttributes struct {
-	Amount       big.Int `json:"amount"`
+	// amount in base units of the chain or token
+	Amount *big.Int `json:"amount,omitempty"`
+	// decimal amount in whole coins, mutually exclusive with amount
+	HumanAmount *string `json:"human_amount,omitempty"`
+	// proof of receiver address ownership, required if ownership verification is enabled and address is not bound to the user yet
+	Ownership *OwnershipProof `json:"ownership,omitempty"`
+	// public key receiver account is created with, only for near chains with account creation enabled
+	PublicKey    *string `json:"public_key,omitempty"`
 	To           string  `json:"to"`
 	TokenAddress *string `json:"token_address,omitempty"`
 }
@@ -0,0 +1,31 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type SendResult struct {
+	Key
+	Attributes SendResultAttributes `json:"attributes"`
+}
+type SendResultResponse struct {
+	Data     SendResult `json:"data"`
+	Included Included   `json:"included"`
+}
+
+type SendResultListResponse struct {
+	Data     []SendResult `json:"data"`
+	Included Included     `json:"included"`
+	Links    *Links       `json:"links"`
+}
+
+// MustSendResult - returns SendResult from include collection.
+// if entry with specified key does not exist - returns nil
+// if entry with specified key exists but type or ID mismatches - panics
+func (c *Included) MustSendResult(key Key) *SendResult {
+	var sendResult SendResult
+	if c.tryFindEntry(key, &sendResult) {
+		return &sendResult
+	}
+	return nil
+}
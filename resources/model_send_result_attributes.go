@@ -0,0 +1,17 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type SendResultAttributes struct {
+	// amount sent in base units, may be less than requested if receiver is topped up to target balance
+	Amount *string `json:"amount,omitempty"`
+	// problem the send failed with, set if status is failed
+	Error *SendResultError `json:"error,omitempty"`
+	// payout status: sent or failed
+	Status string `json:"status"`
+	To     string `json:"to"`
+	// hash of transaction paying the send, transactions may pay several sends of the batch
+	TxHash *string `json:"tx_hash,omitempty"`
+}
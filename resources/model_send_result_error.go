@@ -0,0 +1,17 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "encoding/json"
+
+type SendResultError struct {
+	// application specific problem code, the same as of single send
+	Code   *string `json:"code,omitempty"`
+	Detail *string `json:"detail,omitempty"`
+	// problem specific details
+	Meta   *json.RawMessage `json:"meta,omitempty"`
+	Status string           `json:"status"`
+	Title  string           `json:"title"`
+}
@@ -4,8 +4,16 @@
 
 package resources
 
+import "time"
+
 type TokenAttributes struct {
-	Balance float64 `json:"balance"`
-	Name    string  `json:"name"`
-	Symbol  string  `json:"symbol"`
+	Balance *string `json:"balance,omitempty"`
+	// reason why the balance could not be refreshed
+	Error *string `json:"error,omitempty"`
+	Name  string  `json:"name"`
+	// balance was not refreshed recently and may be outdated
+	Stale  bool   `json:"stale"`
+	Symbol string `json:"symbol"`
+	// time of the last successful balance refresh
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
 }
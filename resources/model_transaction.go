@@ -6,6 +6,7 @@ package resources
 
 type Transaction struct {
 	// transaction hash
-	Id   string `json:"id"`
-	Type string `json:"type"`
+	Id         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Attributes *TransactionAttributes `json:"attributes,omitempty"`
 }
@@ -0,0 +1,12 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type TransactionAttributes struct {
+	// amount sent in base units, may be less than requested if receiver is topped up to target balance
+	Amount string `json:"amount"`
+	// payout status: pending, sent or failed
+	Status string `json:"status"`
+}
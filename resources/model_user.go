@@ -5,6 +5,7 @@
 package resources
 
 type User struct {
-	Id   string `json:"id"`
-	Type string `json:"type"`
+	Id         string          `json:"id"`
+	Type       string          `json:"type"`
+	Attributes *UserAttributes `json:"attributes,omitempty"`
 }
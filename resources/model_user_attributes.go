@@ -0,0 +1,9 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+type UserAttributes struct {
+	Roles []string `json:"roles"`
+}
@@ -0,0 +1,24 @@
+/*
+ * GENERATED. Do not modify. Your changes might be overwritten!
+ */
+
+package resources
+
+import "time"
+
+type WalletAttributes struct {
+	// faucet signer address
+	Address string `json:"address"`
+	// faucet balance in whole coins, omitted if it was never fetched
+	Balance   *string `json:"balance,omitempty"`
+	ChainId   string  `json:"chain_id"`
+	ChainType string  `json:"chain_type"`
+	// reason balance could not be refreshed
+	Error *string `json:"error,omitempty"`
+	// number of payouts waiting for confirmation
+	PendingPayouts int64 `json:"pending_payouts"`
+	// true if balance was not refreshed recently
+	Stale        bool       `json:"stale"`
+	TokenAddress *string    `json:"token_address,omitempty"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}